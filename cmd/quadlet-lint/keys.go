@@ -0,0 +1,78 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	generated "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
+)
+
+const keysCommand = "keys"
+
+// runKeys lists the keys of every group or of the group given with the --group flag along with their syntax and
+// the summary of their documentation
+func runKeys(args []string, w io.Writer) error {
+	flags := flag.NewFlagSet(keysCommand, flag.ContinueOnError)
+	flags.SetOutput(w)
+	group := flags.String("group", "", "Only list the keys of this group like Container")
+	podmanVersion := flags.String("podman-version", "",
+		"Only list the keys supported by this Podman version like 4.9 or 5.3.1. Defaults to the version of the model")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	fields := generated.Fields
+	if *podmanVersion != "" {
+		version, err := model.FindPodmanVersion(*podmanVersion)
+		if err != nil {
+			return err
+		}
+		fields = version.Fields
+	}
+
+	groups := slices.Sorted(maps.Keys(fields))
+	if *group != "" {
+		if _, ok := fields[*group]; !ok {
+			return fmt.Errorf("group '%s' does not exist. Groups: %s", *group, strings.Join(groups, ", "))
+		}
+		groups = []string{*group}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0) //nolint:mnd
+	for i, group := range groups {
+		if i > 0 {
+			fmt.Fprintln(tw)
+		}
+		fmt.Fprintf(tw, "[%s]\n", group)
+		for _, key := range slices.Sorted(maps.Keys(fields[group])) {
+			field := generated.DocumentedField(fields[group][key])
+			if summary := keySummary(field); summary != "" {
+				fmt.Fprintf(tw, "  %s\t%s\n", keySyntax(field), summary)
+			} else {
+				fmt.Fprintf(tw, "  %s\n", keySyntax(field))
+			}
+		}
+	}
+	return tw.Flush()
+}
+
+func keySyntax(field model.Field) string {
+	if field.Doc != nil && field.Doc.Syntax != "" {
+		return field.Doc.Syntax
+	}
+	return field.Key + "="
+}
+
+func keySummary(field model.Field) string {
+	summary := field.Summary()
+	if field.Deprecated {
+		summary = strings.TrimSpace("(deprecated) " + summary)
+	}
+	return summary
+}
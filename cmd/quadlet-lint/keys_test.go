@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	generated "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/container"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunKeys(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.NoError(t, runKeys([]string{"--group", "Pod"}, &out))
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	assert.Equal(t, "[Pod]", lines[0])
+	assert.Contains(t, out.String(), "  PodName=")
+	assert.NotContains(t, out.String(), "[Container]")
+
+	out.Reset()
+	require.NoError(t, runKeys(nil, &out))
+	assert.Contains(t, out.String(), "[Container]\n")
+	assert.Contains(t, out.String(), "\n\n[Pod]\n")
+}
+
+func TestDocumentedField(t *testing.T) {
+	t.Parallel()
+
+	version, err := model.FindPodmanVersion("4.9")
+	require.NoError(t, err)
+	assert.Equal(t, container.Image, generated.DocumentedField(version.Fields["Container"]["Image"]))
+
+	unknown := model.Field{Group: "Container", Key: "Unknown"}
+	assert.Equal(t, unknown, generated.DocumentedField(unknown))
+}
+
+func TestRunKeysErrors(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	err := runKeys([]string{"--group", "Unknown"}, &out)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "group 'Unknown' does not exist. Groups: ")
+
+	err = runKeys([]string{"--podman-version", "1.0"}, &out)
+	require.Error(t, err)
+}
+
+func TestKeySyntaxAndSummary(t *testing.T) {
+	t.Parallel()
+
+	field := model.Field{Group: "Container", Key: "Pull"}
+	assert.Equal(t, "Pull=", keySyntax(field))
+	assert.Empty(t, keySummary(field))
+
+	field.Deprecated = true
+	assert.Equal(t, "(deprecated)", keySummary(field))
+
+	field.Doc = &model.FieldDoc{Syntax: "Pull=never", Description: "Set the image pull policy. The default is missing."}
+	assert.Equal(t, "Pull=never", keySyntax(field))
+	assert.Equal(t, "(deprecated) Set the image pull policy.", keySummary(field))
+}
@@ -1,42 +1,71 @@
 package main
 
 import (
+	"errors"
 	"flag"
 	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"slices"
 
+	"github.com/AhmedMoalla/quadlet-lint/pkg/lint"
 	"github.com/AhmedMoalla/quadlet-lint/pkg/model"
-	"github.com/AhmedMoalla/quadlet-lint/pkg/parser"
 	"github.com/AhmedMoalla/quadlet-lint/pkg/validator"
-	"github.com/AhmedMoalla/quadlet-lint/pkg/validator/common"
-	"github.com/AhmedMoalla/quadlet-lint/pkg/validator/quadlet"
 )
 
 var (
-	checkReferences = flag.Bool("check-references", false, "Check references to other Quadlet files")
+	checkReferences     = flag.Bool("check-references", false, "Check references to other Quadlet files")
+	checkInstallSection = flag.Bool("check-install-section", false,
+		"Warn about long-running units without an [Install] section")
+	podmanVersion = flag.String("podman-version", "",
+		"Podman version targeted by the unit files like 4.9 or 5.3.1. Defaults to the version of the model")
+	minPodmanVersion = flag.String("min-podman-version", "",
+		"Oldest Podman version running the unit files like 4.9. Keys requiring a newer version are reported")
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == keysCommand {
+		if err := runKeys(os.Args[2:], os.Stdout); err != nil && !errors.Is(err, flag.ErrHelp) {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	flag.Parse()
 
+	version, err := targetPodmanVersion(*podmanVersion)
+	if err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+
+	if *minPodmanVersion != "" && !model.IsPodmanVersion(*minPodmanVersion) {
+		fmt.Printf("minimum podman version '%s' is not a version like 4.9 or 5.3.1\n", *minPodmanVersion)
+		os.Exit(1)
+	}
+
 	inputPath := readInputPath()
-	unitFilesPaths := findUnitFiles(inputPath)
-	if len(unitFilesPaths) == 0 {
+	fsys, root := inputFS(inputPath)
+	result, err := lint.Lint(fsys, root, validator.Options{
+		CheckReferences:     *checkReferences,
+		CheckInstallSection: *checkInstallSection,
+		PodmanVersion:       version,
+		MinPodmanVersion:    *minPodmanVersion,
+	})
+	if err != nil {
+		fmt.Printf("could not lint %s: %s\n", inputPath, err)
+		os.Exit(1)
+	}
+
+	if len(result.Paths) == 0 {
 		fmt.Printf("no unit files were found in %s\n", inputPath)
 		os.Exit(0)
 	}
 
-	unitFiles, parsingErrors := parseUnitFiles(unitFilesPaths)
-
-	validationErrors := validateUnitFiles(unitFiles, *checkReferences)
+	reportErrors(result.Errors)
 
-	errors := validationErrors.Merge(parsingErrors)
-	reportErrors(errors)
-
-	logSummary(unitFilesPaths, errors)
+	logSummary(result.Paths, result.Errors)
 }
 
 func logSummary(unitFiles []string, errors validator.ValidationErrors) {
@@ -61,50 +90,21 @@ func readInputPath() string {
 	return inputDirOrFile
 }
 
-func findUnitFiles(inputDirOrFile string) []string {
-	var unitFilesPaths []string
+// inputFS returns the filesystem holding the input path and the root of the unit files to lint in it
+func inputFS(inputDirOrFile string) (fs.FS, string) {
 	if isDir(inputDirOrFile) {
-		unitFilesPaths = getAllUnitFiles(inputDirOrFile)
-	} else {
-		unitFilesPaths = []string{inputDirOrFile}
+		return os.DirFS(inputDirOrFile), "."
 	}
-	return unitFilesPaths
+	return os.DirFS(filepath.Dir(inputDirOrFile)), filepath.Base(inputDirOrFile)
 }
 
-var ParsingError = validator.ErrorCategory{
-	Name:  "parsing-error",
-	Level: validator.LevelError,
-}
-
-func parseUnitFiles(unitFilesPaths []string) ([]model.UnitFile, validator.ValidationErrors) {
-	errors := make(validator.ValidationErrors)
-	unitFiles := make([]model.UnitFile, 0, len(unitFilesPaths))
-	for _, path := range unitFilesPaths {
-		unitFile, errs := parser.ParseUnitFile(path)
-		if unitFile != nil {
-			unitFiles = append(unitFiles, unitFile)
-		}
-
-		for _, err := range errs {
-			errors.AddError(path, *ParsingError.Err("", err.Group, err.Key, err.Line, err.Column, err.Error()))
-		}
+// targetPodmanVersion returns the Podman version matching the --podman-version flag. No version is targeted when the
+// flag is not set.
+func targetPodmanVersion(version string) (model.PodmanVersion, error) {
+	if version == "" {
+		return model.PodmanVersion{}, nil
 	}
-	return unitFiles, errors
-}
-
-func validateUnitFiles(unitFiles []model.UnitFile, checkReferences bool) validator.ValidationErrors {
-	validationErrors := make(validator.ValidationErrors)
-	validators := []validator.Validator{
-		common.Validator(),
-		quadlet.Validator(unitFiles, validator.Options{CheckReferences: checkReferences}),
-	}
-
-	for _, file := range unitFiles {
-		for _, vtor := range validators {
-			validationErrors.AddError(file.FileName(), vtor.Validate(file)...)
-		}
-	}
-	return validationErrors
+	return model.FindPodmanVersion(version)
 }
 
 func reportErrors(errors validator.ValidationErrors) {
@@ -121,8 +121,13 @@ func reportErrors(errors validator.ValidationErrors) {
 				if validatorName != "" {
 					validatorName += "."
 				}
-				fmt.Printf("\t-> [%s][%s%s][%d:%d] %s\n",
-					err.Level, validatorName, err.ErrorCategory.Name, err.Line, err.Column, err.Error)
+				position := fmt.Sprintf("%d:%d", err.Line, err.Column)
+				if err.FilePath != "" {
+					// The error is located in a drop-in of the unit file
+					position = err.FilePath + ":" + position
+				}
+				fmt.Printf("\t-> [%s][%s%s][%s] %s\n",
+					err.Level, validatorName, err.ErrorCategory.Name, position, err.Error)
 			}
 		}
 	}
@@ -145,24 +150,3 @@ func isDir(path string) bool {
 
 	return fileInfo.IsDir()
 }
-
-func getAllUnitFiles(rootDirectory string) []string {
-	unitFilesPaths := make([]string, 0)
-	err := filepath.WalkDir(rootDirectory, func(path string, entry fs.DirEntry, err error) error {
-		if entry.IsDir() && entry.Name() == ".git" {
-			return filepath.SkipDir
-		}
-
-		if slices.Contains(model.AllUnitFileExtensions, filepath.Ext(path)) {
-			unitFilesPaths = append(unitFilesPaths, path)
-		}
-
-		return nil
-	})
-
-	if err != nil {
-		panic(err)
-	}
-
-	return unitFilesPaths
-}
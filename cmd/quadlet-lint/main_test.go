@@ -6,6 +6,9 @@ import (
 	"path/filepath"
 	"testing"
 
+	"github.com/AhmedMoalla/quadlet-lint/pkg/lint"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/container"
+	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
 	"github.com/AhmedMoalla/quadlet-lint/pkg/validator/quadlet"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -13,44 +16,64 @@ import (
 
 const testDataDir = "testdata"
 
-func TestUnitFiles(t *testing.T) {
+func TestInputFS(t *testing.T) {
 	t.Parallel()
 
-	pod := filepath.Join(testDataDir, "test.pod")
-
-	files := findUnitFiles(testDataDir)
+	fsys, root := inputFS(testDataDir)
+	assert.Equal(t, ".", root)
+	files, err := lint.FindUnitFiles(fsys, root)
+	require.NoError(t, err)
 	assert.Len(t, files, 3)
-	assert.Contains(t, files, filepath.Join(testDataDir, "test.container"))
-	assert.Contains(t, files, pod)
+	assert.Contains(t, files, "test.container")
+	assert.Contains(t, files, "test.pod")
 
-	files = findUnitFiles(pod)
-	assert.Len(t, files, 1)
-	assert.Equal(t, files[0], pod)
+	fsys, root = inputFS(filepath.Join(testDataDir, "test.pod"))
+	assert.Equal(t, "test.pod", root)
+	files, err = lint.FindUnitFiles(fsys, root)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"test.pod"}, files)
 
-	assert.Panics(t, func() { findUnitFiles("not-exists") })
+	assert.Panics(t, func() { inputFS("not-exists") })
 }
 
-func TestParseUnitFiles(t *testing.T) {
+func TestLintInput(t *testing.T) {
 	t.Parallel()
 
-	paths := findUnitFiles(testDataDir)
-
-	units, errs := parseUnitFiles(paths)
-	assert.Len(t, units, 2)
-	assert.Len(t, errs, 1)
+	fsys, root := inputFS(testDataDir)
+	result, err := lint.Lint(fsys, root, V.Options{CheckReferences: *checkReferences})
+	require.NoError(t, err)
+	assert.Len(t, result.Paths, 3)
+
+	// test.pod has a parsing error but is still validated
+	assert.Len(t, result.Errors, 3)
+	require.Len(t, result.Errors["test.container"], 1)
+	assert.Equal(t, quadlet.AmbiguousImageName, result.Errors["test.container"][0].ErrorCategory)
+	require.Len(t, result.Errors["test.pod"], 1)
+	assert.Equal(t, lint.ParsingError, result.Errors["test.pod"][0].ErrorCategory)
 }
 
-func TestValidateUnitFiles(t *testing.T) {
+func TestTargetPodmanVersion(t *testing.T) {
 	t.Parallel()
 
-	paths := findUnitFiles(testDataDir)
-	units, _ := parseUnitFiles(paths)
-	assert.Len(t, units, 2)
+	version, err := targetPodmanVersion("")
+	require.NoError(t, err)
+	assert.Nil(t, version.Fields)
 
-	errs := validateUnitFiles(units, *checkReferences)
-	assert.Len(t, errs, 2)
-	assert.Len(t, errs["test.container"], 1)
-	assert.Equal(t, errs["test.container"][0].ErrorCategory, quadlet.AmbiguousImageName)
+	// The versions of the generated model are registered
+	version, err = targetPodmanVersion("5.3")
+	require.NoError(t, err)
+	assert.Equal(t, "v5.3.1", version.Name)
+	assert.True(t, version.HasField(container.Image))
+
+	// Older versions are generated along with the model
+	version, err = targetPodmanVersion("4.9")
+	require.NoError(t, err)
+	assert.Equal(t, "v4.9.5", version.Name)
+	assert.True(t, version.HasField(container.Image))
+	assert.False(t, version.HasField(container.HealthLogDestination))
+
+	_, err = targetPodmanVersion("1.0")
+	require.Error(t, err)
 }
 
 func TestReadInputPath(t *testing.T) {
@@ -78,10 +101,12 @@ func TestLogSummary(t *testing.T) {
 
 	os.Stdout = fileStdout
 
-	paths := findUnitFiles(testDataDir)
-	units, _ := parseUnitFiles(paths)
-	assert.Len(t, units, 2)
-	errs := validateUnitFiles(units, *checkReferences)
+	fsys, root := inputFS(testDataDir)
+	paths, err := lint.FindUnitFiles(fsys, root)
+	require.NoError(t, err)
+	units, _ := lint.ParseUnitFiles(fsys, paths)
+	assert.Len(t, units, 3)
+	errs := lint.ValidateUnitFiles(units, V.Options{CheckReferences: *checkReferences})
 	logSummary(paths, errs)
 
 	content, err := os.ReadFile(fileStdout.Name())
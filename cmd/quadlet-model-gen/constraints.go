@@ -0,0 +1,478 @@
+package main
+
+import (
+	"go/ast"
+	"go/token"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const convertFuncPrefix = "Convert"
+
+// dependencyErrorRegexp matches the errors of the checks like "key Type can't be used without Device" or
+// "invalid Group set without User"
+var dependencyErrorRegexp = regexp.MustCompile(`^(?:key |invalid )?(\w+) (?:can't be used|set) without (\w+)$`)
+
+// keyConstraints are the constraints that Quadlet enforces on a key when converting a unit
+type keyConstraints struct {
+	AllowedValues []string
+	DependsOn     []string
+}
+
+// constraintsByUnitType holds the constraints of every key by unit type, group and key
+type constraintsByUnitType map[string]map[string]map[string]keyConstraints
+
+func (c constraintsByUnitType) add(unitType, group, key string, constraints keyConstraints) {
+	if c[unitType] == nil {
+		c[unitType] = make(map[string]map[string]keyConstraints)
+	}
+	if c[unitType][group] == nil {
+		c[unitType][group] = make(map[string]keyConstraints)
+	}
+
+	existing := c[unitType][group][key]
+	existing.AllowedValues = mergeSorted(existing.AllowedValues, constraints.AllowedValues)
+	existing.DependsOn = mergeSorted(existing.DependsOn, constraints.DependsOn)
+	c[unitType][group][key] = existing
+}
+
+func mergeSorted(values, others []string) []string {
+	merged := slices.Concat(values, others)
+	slices.Sort(merged)
+	return slices.Compact(merged)
+}
+
+// convertContext is a unit type converted by a Convert function along with the group that a function called while
+// converting it is given
+type convertContext struct {
+	unitType string
+	group    string
+}
+
+// lookupBinding is the group and the key of the value a variable was assigned with by a lookup
+type lookupBinding struct {
+	group string
+	key   string
+}
+
+// inspectQuadletSourceFileConstraints walks the bodies of the Convert functions and of the functions they call to
+// extract the constraints that they enforce with an error:
+//   - the cases of a switch on a looked up value whose default case returns an error are its allowed values
+//   - the values compared to a looked up value in a condition returning an error are its allowed values
+//   - the errors like "key X can't be used without Y" make X depend on Y
+func inspectQuadletSourceFileConstraints(
+	file *ast.File,
+	declarations declarations,
+	fieldsByGroup map[string][]field,
+) constraintsByUnitType {
+	funcs := make(map[string]*ast.FuncDecl)
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv == nil && decl.Body != nil {
+			funcs[decl.Name.Name] = decl
+		}
+	}
+
+	constraints := make(constraintsByUnitType)
+	for name, contexts := range inspectConvertContexts(funcs, declarations) {
+		for context := range contexts {
+			inspectFuncConstraints(funcs[name], context, declarations, fieldsByGroup, constraints)
+		}
+	}
+	return constraints
+}
+
+// inspectConvertContexts returns the contexts in which every function is called starting from the Convert functions.
+// The group given to a function is followed through its group parameter.
+func inspectConvertContexts(
+	funcs map[string]*ast.FuncDecl,
+	declarations declarations,
+) map[string]map[convertContext]bool {
+	contexts := make(map[string]map[convertContext]bool, len(funcs))
+	type call struct {
+		name    string
+		context convertContext
+	}
+
+	queue := make([]call, 0)
+	for name := range funcs {
+		if unitType, ok := strings.CutPrefix(name, convertFuncPrefix); ok && unitType != "" {
+			queue = append(queue, call{name: name, context: convertContext{unitType: strings.ToLower(unitType)}})
+		}
+	}
+
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if contexts[current.name][current.context] {
+			continue
+		}
+		if contexts[current.name] == nil {
+			contexts[current.name] = make(map[convertContext]bool)
+		}
+		contexts[current.name][current.context] = true
+
+		caller := funcs[current.name]
+		callerGroupParam := groupParamName(caller)
+		ast.Inspect(caller.Body, func(n ast.Node) bool {
+			callExpr, ok := n.(*ast.CallExpr)
+			if !ok {
+				return true
+			}
+
+			ident, ok := callExpr.Fun.(*ast.Ident)
+			if !ok || funcs[ident.Name] == nil {
+				return true
+			}
+
+			callee := funcs[ident.Name]
+			context := convertContext{unitType: current.context.unitType}
+			if index := groupParamIndex(callee); index >= 0 && index < len(callExpr.Args) {
+				group, ok := resolveGroup(callExpr.Args[index], declarations, callerGroupParam, current.context)
+				if !ok {
+					return true
+				}
+				context.group = group
+			}
+			queue = append(queue, call{name: ident.Name, context: context})
+			return true
+		})
+	}
+	return contexts
+}
+
+// groupParamIndex returns the index of the string parameter of a function holding the name of a group like groupName
+func groupParamIndex(decl *ast.FuncDecl) int {
+	index := 0
+	for _, param := range decl.Type.Params.List {
+		for _, name := range param.Names {
+			if ident, ok := param.Type.(*ast.Ident); ok && ident.Name == "string" &&
+				strings.Contains(strings.ToLower(name.Name), "group") {
+				return index
+			}
+			index++
+		}
+	}
+	return -1
+}
+
+func groupParamName(decl *ast.FuncDecl) string {
+	index := 0
+	for _, param := range decl.Type.Params.List {
+		for _, name := range param.Names {
+			if index == groupParamIndex(decl) {
+				return name.Name
+			}
+			index++
+		}
+	}
+	return ""
+}
+
+// resolveGroup returns the name of the group an expression refers to. It is either a group constant or the group
+// parameter of the function.
+func resolveGroup(expr ast.Expr, declarations declarations, groupParam string, context convertContext) (string, bool) {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+
+	if group, ok := declarations.groupConstants[ident.Name]; ok {
+		return group, true
+	}
+
+	if ident.Name == groupParam && context.group != "" {
+		return context.group, true
+	}
+	return "", false
+}
+
+// resolveKey returns the name of the key an expression refers to. It is either a key constant or a string literal.
+func resolveKey(expr ast.Expr, declarations declarations) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		key, ok := declarations.keyConstants[expr.Name]
+		return key, ok
+	case *ast.BasicLit:
+		return stringLiteral(expr)
+	default:
+		return "", false
+	}
+}
+
+func stringLiteral(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+func inspectFuncConstraints(
+	decl *ast.FuncDecl,
+	context convertContext,
+	declarations declarations,
+	fieldsByGroup map[string][]field,
+	constraints constraintsByUnitType,
+) {
+	groupParam := groupParamName(decl)
+	bindings := make(map[string]lookupBinding)
+	addAllowedValues := func(variable string, values []string) {
+		binding, ok := bindings[variable]
+		values = slices.DeleteFunc(values, func(value string) bool { return value == "" })
+		if ok && len(values) > 0 && hasField(fieldsByGroup, binding.group, binding.key) {
+			constraints.add(context.unitType, binding.group, binding.key, keyConstraints{AllowedValues: values})
+		}
+	}
+
+	bind := func(assign *ast.AssignStmt) {
+		if variable, binding, ok := inspectLookupAssignment(assign, declarations, groupParam, context); ok {
+			bindings[variable] = binding
+			return
+		}
+
+		for _, lhs := range assign.Lhs {
+			if ident, ok := lhs.(*ast.Ident); ok {
+				delete(bindings, ident.Name)
+			}
+		}
+	}
+
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			bind(n)
+		case *ast.SwitchStmt:
+			if init, ok := n.Init.(*ast.AssignStmt); ok {
+				bind(init)
+			}
+			if variable, values, ok := inspectSwitchAllowedValues(n); ok {
+				addAllowedValues(variable, values)
+			}
+		case *ast.IfStmt:
+			// The condition is inspected before the children of the statement so its init is bound first
+			if init, ok := n.Init.(*ast.AssignStmt); ok {
+				bind(init)
+			}
+			if returnsError(n.Body) {
+				for variable, values := range inspectConditionAllowedValues(n.Cond) {
+					addAllowedValues(variable, values)
+				}
+			}
+		case *ast.CallExpr:
+			if key, dependency, ok := inspectDependencyError(n); ok {
+				group := context.group
+				if group == "" {
+					group = strings.ToUpper(context.unitType[:1]) + context.unitType[1:]
+				}
+				if hasField(fieldsByGroup, group, key) && hasField(fieldsByGroup, group, dependency) {
+					constraints.add(context.unitType, group, key, keyConstraints{DependsOn: []string{dependency}})
+				}
+			}
+		}
+		return true
+	})
+}
+
+func hasField(fieldsByGroup map[string][]field, group, key string) bool {
+	return slices.ContainsFunc(fieldsByGroup[group], func(field field) bool { return field.Key == key })
+}
+
+// inspectLookupAssignment finds the assignments like 'value, ok := unit.Lookup(Group, Key)'
+func inspectLookupAssignment(
+	assign *ast.AssignStmt,
+	declarations declarations,
+	groupParam string,
+	context convertContext,
+) (string, lookupBinding, bool) {
+	if len(assign.Lhs) == 0 || len(assign.Rhs) != 1 {
+		return "", lookupBinding{}, false
+	}
+
+	variable, ok := assign.Lhs[0].(*ast.Ident)
+	if !ok {
+		return "", lookupBinding{}, false
+	}
+
+	call, ok := assign.Rhs[0].(*ast.CallExpr)
+	if !ok || len(call.Args) < 2 {
+		return "", lookupBinding{}, false
+	}
+
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || !strings.HasPrefix(selector.Sel.Name, "Lookup") {
+		return "", lookupBinding{}, false
+	}
+
+	group, ok := resolveGroup(call.Args[0], declarations, groupParam, context)
+	if !ok {
+		return "", lookupBinding{}, false
+	}
+
+	key, ok := resolveKey(call.Args[1], declarations)
+	if !ok {
+		return "", lookupBinding{}, false
+	}
+
+	return variable.Name, lookupBinding{group: group, key: key}, true
+}
+
+// inspectSwitchAllowedValues returns the cases of a switch on a variable whose default case returns an error
+func inspectSwitchAllowedValues(switchStmt *ast.SwitchStmt) (string, []string, bool) {
+	variable, ok := switchStmt.Tag.(*ast.Ident)
+	if !ok {
+		return "", nil, false
+	}
+
+	values := make([]string, 0)
+	hasErrorDefault := false
+	for _, stmt := range switchStmt.Body.List {
+		clause, ok := stmt.(*ast.CaseClause)
+		if !ok {
+			continue
+		}
+
+		if clause.List == nil {
+			hasErrorDefault = slices.ContainsFunc(clause.Body, isErrorReturn)
+			continue
+		}
+
+		for _, expr := range clause.List {
+			value, ok := stringLiteral(expr)
+			if !ok {
+				return "", nil, false
+			}
+			values = append(values, value)
+		}
+	}
+
+	return variable.Name, values, hasErrorDefault
+}
+
+// inspectConditionAllowedValues returns the values compared to variables in conditions like
+// 'ok && value != "a" && value != "b"' or '!ok || !(value == "a" || value == "b")'
+func inspectConditionAllowedValues(cond ast.Expr) map[string][]string {
+	valuesByVariable := make(map[string][]string)
+
+	// value != "a" && value != "b"
+	for _, operand := range flattenBinaryExpr(cond, token.LAND) {
+		if variable, value, ok := stringComparison(operand, token.NEQ); ok {
+			valuesByVariable[variable] = append(valuesByVariable[variable], value)
+		}
+	}
+
+	// !(value == "a" || value == "b")
+	ast.Inspect(cond, func(n ast.Node) bool {
+		not, ok := n.(*ast.UnaryExpr)
+		if !ok || not.Op != token.NOT {
+			return true
+		}
+
+		operands := flattenBinaryExpr(not.X, token.LOR)
+		values := make([]string, 0, len(operands))
+		var variable string
+		for _, operand := range operands {
+			operandVariable, value, ok := stringComparison(operand, token.EQL)
+			if !ok || (variable != "" && operandVariable != variable) {
+				return true
+			}
+			variable = operandVariable
+			values = append(values, value)
+		}
+		valuesByVariable[variable] = append(valuesByVariable[variable], values...)
+		return false
+	})
+	return valuesByVariable
+}
+
+// flattenBinaryExpr returns the operands of a chain of binary expressions using the same operator
+func flattenBinaryExpr(expr ast.Expr, op token.Token) []ast.Expr {
+	switch e := expr.(type) {
+	case *ast.ParenExpr:
+		return flattenBinaryExpr(e.X, op)
+	case *ast.BinaryExpr:
+		if e.Op == op {
+			return append(flattenBinaryExpr(e.X, op), flattenBinaryExpr(e.Y, op)...)
+		}
+	}
+	return []ast.Expr{expr}
+}
+
+// stringComparison matches comparisons of a variable with a string literal like 'value == "a"'
+func stringComparison(expr ast.Expr, op token.Token) (string, string, bool) {
+	binary, ok := expr.(*ast.BinaryExpr)
+	if !ok || binary.Op != op {
+		return "", "", false
+	}
+
+	variable, ok := binary.X.(*ast.Ident)
+	if !ok {
+		return "", "", false
+	}
+
+	value, ok := stringLiteral(binary.Y)
+	return variable.Name, value, ok
+}
+
+// returnsError is true when a block returns an error created with fmt.Errorf
+func returnsError(block *ast.BlockStmt) bool {
+	found := false
+	ast.Inspect(block, func(n ast.Node) bool {
+		if _, ok := n.(*ast.FuncLit); ok {
+			return false
+		}
+		if stmt, ok := n.(ast.Stmt); ok && isErrorReturn(stmt) {
+			found = true
+		}
+		return !found
+	})
+	return found
+}
+
+func isErrorReturn(stmt ast.Stmt) bool {
+	ret, ok := stmt.(*ast.ReturnStmt)
+	if !ok {
+		return false
+	}
+	return slices.ContainsFunc(ret.Results, func(result ast.Expr) bool {
+		_, ok := errorfFormat(result)
+		return ok
+	})
+}
+
+// errorfFormat returns the format of a call to fmt.Errorf
+func errorfFormat(expr ast.Expr) (string, bool) {
+	call, ok := expr.(*ast.CallExpr)
+	if !ok || len(call.Args) == 0 {
+		return "", false
+	}
+
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || selector.Sel.Name != "Errorf" {
+		return "", false
+	}
+
+	if pkg, ok := selector.X.(*ast.Ident); !ok || pkg.Name != "fmt" {
+		return "", false
+	}
+	return stringLiteral(call.Args[0])
+}
+
+// inspectDependencyError returns the keys of an error like "key X can't be used without Y"
+func inspectDependencyError(call *ast.CallExpr) (string, string, bool) {
+	format, ok := errorfFormat(call)
+	if !ok {
+		return "", "", false
+	}
+
+	matches := dependencyErrorRegexp.FindStringSubmatch(format)
+	if matches == nil {
+		return "", "", false
+	}
+	return matches[1], matches[2], true
+}
@@ -0,0 +1,132 @@
+package main
+
+import (
+	"go/parser"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuadletParserConstraints(t *testing.T) {
+	t.Parallel()
+
+	lookupFuncs, err := parseUnitFileGo()
+	require.NoError(t, err)
+
+	_, constraints, err := parseQuadletPackage(quadletTestFile, unitfileTestFile, lookupFuncs)
+	require.NoError(t, err)
+
+	remapUsers := keyConstraints{AllowedValues: []string{"auto", "keep-id", "manual"}}
+	service := map[string]keyConstraints{
+		"KillMode": {AllowedValues: []string{"control-group", "mixed"}},
+		"Type":     {AllowedValues: []string{"notify", "oneshot"}},
+	}
+	assert.Equal(t, constraintsByUnitType{
+		"container": {
+			"Container": {
+				"Group":      {DependsOn: []string{"User"}},
+				"RemapUsers": remapUsers,
+			},
+			"Service": service,
+		},
+		"kube": {
+			"Kube":    {"RemapUsers": remapUsers},
+			"Service": service,
+		},
+		"pod": {
+			"Pod": {"RemapUsers": remapUsers},
+		},
+		"volume": {
+			"Volume": {
+				"Options": {DependsOn: []string{"Device"}},
+				"Type":    {DependsOn: []string{"Device"}},
+			},
+		},
+	}, constraints)
+}
+
+const constraintsSource = `package quadlet
+
+import (
+	"fmt"
+
+	"github.com/containers/podman/v5/pkg/systemd/parser"
+)
+
+const (
+	ContainerGroup = "Container"
+	KeyUser        = "User"
+	KeyGroup       = "Group"
+	KeyPull        = "Pull"
+	KeyNotify      = "Notify"
+)
+
+func ConvertContainer(container *parser.UnitFile) error {
+	if pull, ok := container.Lookup(ContainerGroup, KeyPull); ok && pull != "always" && pull != "never" {
+		return fmt.Errorf("invalid Pull '%s'", pull)
+	}
+	return handleNotify(container, ContainerGroup)
+}
+
+func handleNotify(unit *parser.UnitFile, groupName string) error {
+	notify, _ := unit.Lookup(groupName, KeyNotify)
+	switch notify {
+	case "", "healthy":
+		if _, ok := unit.Lookup(groupName, KeyGroup); ok {
+			return fmt.Errorf("key Group can't be used without User")
+		}
+	default:
+		return fmt.Errorf("invalid Notify '%s'", notify)
+	}
+	return nil
+}
+
+func unused(unit *parser.UnitFile) error {
+	pull, _ := unit.Lookup(ContainerGroup, KeyPull)
+	if !(pull == "missing") {
+		return fmt.Errorf("invalid Pull")
+	}
+	return nil
+}
+`
+
+func TestInspectQuadletSourceFileConstraints(t *testing.T) {
+	t.Parallel()
+
+	lookupFuncs, err := parseUnitFileGo()
+	require.NoError(t, err)
+
+	pkg := checkTestPackage(t, constraintsSource)
+	declarations := inspectQuadletPackage(pkg, lookupFuncs).declarations
+	fieldsByGroup := map[string][]field{
+		"Container": {{Key: "User"}, {Key: "Group"}, {Key: "Pull"}, {Key: "Notify"}},
+	}
+
+	// The functions that are not called while converting a unit are ignored
+	assert.Equal(t, constraintsByUnitType{
+		"container": {
+			"Container": {
+				"Pull":   {AllowedValues: []string{"always", "never"}},
+				"Notify": {AllowedValues: []string{"healthy"}},
+				"Group":  {DependsOn: []string{"User"}},
+			},
+		},
+	}, inspectQuadletSourceFileConstraints(pkg.file(), declarations, fieldsByGroup))
+}
+
+func TestInspectConditionAllowedValues(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]map[string][]string{
+		`ok && value != "a" && value != "b"`:     {"value": {"a", "b"}},
+		`!ok || !(value == "a" || value == "b")`: {"value": {"a", "b"}},
+		`value != "a" || other != "b"`:           {},
+		`!(value == "a" || other == "b")`:        {},
+	}
+	for cond, expected := range tests {
+		expr, err := parser.ParseExpr(cond)
+		require.NoError(t, err)
+		assert.Equal(t, expected, inspectConditionAllowedValues(expr), cond)
+	}
+}
@@ -0,0 +1,290 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+const (
+	diffCommand = "diff"
+
+	diffFormatText = "text"
+	diffFormatJSON = "json"
+)
+
+// modelDiff is the report of the keys that changed between two Podman versions
+type modelDiff struct {
+	From    string   `json:"from"`
+	To      string   `json:"to"`
+	Added   []keyRef `json:"added"`
+	Removed []keyRef `json:"removed"`
+	// Deprecated are the keys supported by both versions that are only deprecated by the newer one
+	Deprecated []keyRef    `json:"deprecated"`
+	Changed    []keyChange `json:"changed"`
+}
+
+type keyRef struct {
+	Group       string `json:"group"`
+	Key         string `json:"key"`
+	Replacement string `json:"replacement,omitempty"`
+}
+
+// keyChange lists the properties of a key supported by both versions that changed
+type keyChange struct {
+	Group   string           `json:"group"`
+	Key     string           `json:"key"`
+	Changes []propertyChange `json:"changes"`
+}
+
+type propertyChange struct {
+	Property string `json:"property"`
+	From     string `json:"from"`
+	To       string `json:"to"`
+}
+
+func (d modelDiff) empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Deprecated) == 0 && len(d.Changed) == 0
+}
+
+// runDiff compares the keys parsed from the source files of two Podman versions given as arguments and writes the
+// report to w. The source files of a version are downloaded unless a checkout of Podman is given for it.
+func runDiff(args []string, w io.Writer) error {
+	flags := flag.NewFlagSet(diffCommand, flag.ContinueOnError)
+	flags.SetOutput(w)
+	flags.Usage = func() {
+		fmt.Fprintf(w, "Usage: quadlet-model-gen %s [flags] FROM_VERSION TO_VERSION\n", diffCommand)
+		flags.PrintDefaults()
+	}
+	format := flags.String("format", diffFormatText, "Format of the report: text or json")
+	cache := flags.String(cacheDirFlag, "", "Directory where downloaded source files are cached by Podman's tag")
+	fromSourceDir := flags.String(fromSourceDirFlag, "", "Podman checkout of FROM_VERSION used instead of downloading "+
+		"its source files")
+	toSourceDir := flags.String(toSourceDirFlag, "", "Podman checkout of TO_VERSION used instead of downloading "+
+		"its source files")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if flags.NArg() != 2 { //nolint:mnd
+		flags.Usage()
+		return fmt.Errorf("expected 2 Podman versions but got %d", flags.NArg())
+	}
+	if *format != diffFormatText && *format != diffFormatJSON {
+		return fmt.Errorf("unknown format '%s'. Use %s or %s", *format, diffFormatText, diffFormatJSON)
+	}
+
+	sourceDirs := []string{*fromSourceDir, *toSourceDir}
+	versions := make([]sourceFileData, 0, flags.NArg())
+	for i, version := range flags.Args() {
+		data, err := loadSourceFileData(sourceOptions{
+			podmanVersion: version,
+			sourceDir:     sourceDirs[i],
+			cacheDir:      getCacheDir(*cache),
+			baseURL:       podmanGithubTagsURL,
+		})
+		if err != nil {
+			return fmt.Errorf("could not load Podman %s: %w", version, err)
+		}
+		versions = append(versions, data)
+	}
+
+	diff := diffModels(versions[0], versions[1])
+	if *format == diffFormatJSON {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(diff)
+	}
+	return writeDiffText(w, diff)
+}
+
+// loadSourceFileData resolves and parses the source files of a Podman version
+func loadSourceFileData(options sourceOptions) (sourceFileData, error) {
+	files, err := resolveSourceFiles(options)
+	if err != nil {
+		return sourceFileData{}, err
+	}
+	defer files.remove()
+
+	unitfileParserFile, err := os.Open(files.unitfile)
+	if err != nil {
+		return sourceFileData{}, fmt.Errorf("could not open unitfile.go source file: %w", err)
+	}
+	defer unitfileParserFile.Close()
+
+	quadletSourceFile, err := os.Open(files.quadlet)
+	if err != nil {
+		return sourceFileData{}, fmt.Errorf("could not open quadlet.go source file: %w", err)
+	}
+	defer quadletSourceFile.Close()
+
+	return parseSourceFiles(quadletSourceFile, unitfileParserFile, options.podmanVersion)
+}
+
+// diffModels compares the keys of two versions by group and key
+func diffModels(from, to sourceFileData) modelDiff {
+	diff := modelDiff{
+		From:       from.podmanVersion,
+		To:         to.podmanVersion,
+		Added:      make([]keyRef, 0),
+		Removed:    make([]keyRef, 0),
+		Deprecated: make([]keyRef, 0),
+		Changed:    make([]keyChange, 0),
+	}
+
+	fromFields, toFields := fieldsByGroupKey(from.fieldsByGroup), fieldsByGroupKey(to.fieldsByGroup)
+	for _, group := range sortedUnion(fromFields, toFields) {
+		for _, key := range sortedUnion(fromFields[group], toFields[group]) {
+			oldField, inFrom := fromFields[group][key]
+			newField, inTo := toFields[group][key]
+			switch {
+			case !inFrom:
+				diff.Added = append(diff.Added, keyRef{Group: group, Key: key, Replacement: newField.Replacement})
+			case !inTo:
+				diff.Removed = append(diff.Removed, keyRef{Group: group, Key: key})
+			default:
+				if newField.Deprecated && !oldField.Deprecated {
+					diff.Deprecated = append(diff.Deprecated,
+						keyRef{Group: group, Key: key, Replacement: newField.Replacement})
+				}
+
+				changes := fieldChanges(oldField, newField)
+				changes = append(changes, constraintChanges(group, key, from.constraints, to.constraints)...)
+				if len(changes) > 0 {
+					diff.Changed = append(diff.Changed, keyChange{Group: group, Key: key, Changes: changes})
+				}
+			}
+		}
+	}
+	return diff
+}
+
+func fieldsByGroupKey(fieldsByGroup map[string][]field) map[string]map[string]field {
+	fields := make(map[string]map[string]field, len(fieldsByGroup))
+	for group, groupFields := range fieldsByGroup {
+		fields[group] = make(map[string]field, len(groupFields))
+		for _, field := range groupFields {
+			fields[group][field.Key] = field
+		}
+	}
+	return fields
+}
+
+// fieldChanges compares the lookup semantics of a key. Its deprecation is reported separately unless it is no longer
+// deprecated.
+func fieldChanges(from, to field) []propertyChange {
+	properties := []propertyChange{
+		{Property: "lookup", From: from.LookupFunc.Name, To: to.LookupFunc.Name},
+		{Property: "kind", From: from.Kind, To: to.Kind},
+		{Property: "multiplicity", From: from.Multiplicity, To: to.Multiplicity},
+		{Property: "unit types", From: strings.Join(from.UnitTypes, ", "), To: strings.Join(to.UnitTypes, ", ")},
+		{Property: "specifier path", From: strconv.FormatBool(from.SpecifierPath), To: strconv.FormatBool(to.SpecifierPath)},
+	}
+	if from.Deprecated && !to.Deprecated {
+		properties = append(properties, propertyChange{Property: "deprecated", From: "true", To: "false"})
+	}
+
+	changes := make([]propertyChange, 0)
+	for _, property := range properties {
+		if property.From != property.To {
+			changes = append(changes, property)
+		}
+	}
+	return changes
+}
+
+// constraintChanges compares the values allowed for a key and the keys it depends on by unit type
+func constraintChanges(group, key string, from, to constraintsByUnitType) []propertyChange {
+	changes := make([]propertyChange, 0)
+	for _, unitType := range sortedUnion(from, to) {
+		oldConstraints := from[unitType][group][key]
+		newConstraints := to[unitType][group][key]
+
+		oldValues, newValues := strings.Join(oldConstraints.AllowedValues, ", "),
+			strings.Join(newConstraints.AllowedValues, ", ")
+		if oldValues != newValues {
+			changes = append(changes, propertyChange{
+				Property: fmt.Sprintf("allowed values (%s)", unitType), From: oldValues, To: newValues,
+			})
+		}
+
+		oldDependencies, newDependencies := strings.Join(oldConstraints.DependsOn, ", "),
+			strings.Join(newConstraints.DependsOn, ", ")
+		if oldDependencies != newDependencies {
+			changes = append(changes, propertyChange{
+				Property: fmt.Sprintf("depends on (%s)", unitType), From: oldDependencies, To: newDependencies,
+			})
+		}
+	}
+	return changes
+}
+
+func sortedUnion[V any](a, b map[string]V) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for key := range b {
+		if _, ok := a[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// writeDiffText writes the report in a format suited to upgrade notes
+func writeDiffText(w io.Writer, diff modelDiff) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Podman %s -> %s\n", diff.From, diff.To)
+	if diff.empty() {
+		b.WriteString("\nNo changes\n")
+	}
+
+	sections := []struct {
+		title string
+		keys  []keyRef
+	}{
+		{"Added keys", diff.Added},
+		{"Removed keys", diff.Removed},
+		{"Deprecated keys", diff.Deprecated},
+	}
+	for _, section := range sections {
+		if len(section.keys) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(&b, "\n%s:\n", section.title)
+		for _, key := range section.keys {
+			fmt.Fprintf(&b, "  [%s] %s", key.Group, key.Key)
+			if key.Replacement != "" {
+				fmt.Fprintf(&b, " (replaced by %s)", key.Replacement)
+			}
+			b.WriteString("\n")
+		}
+	}
+
+	if len(diff.Changed) > 0 {
+		b.WriteString("\nChanged keys:\n")
+		for _, change := range diff.Changed {
+			fmt.Fprintf(&b, "  [%s] %s\n", change.Group, change.Key)
+			for _, property := range change.Changes {
+				fmt.Fprintf(&b, "    %s: %s -> %s\n", property.Property, textValue(property.From),
+					textValue(property.To))
+			}
+		}
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+func textValue(value string) string {
+	if value == "" {
+		return "(none)"
+	}
+	return value
+}
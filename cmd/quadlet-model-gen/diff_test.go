@@ -0,0 +1,157 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// diffCacheDir returns a cache directory holding the source files of v5.3.1 and of an older version derived from them
+func diffCacheDir(t *testing.T) string {
+	t.Helper()
+
+	unitfile, err := os.ReadFile("testdata/v5.3.1/unitfile.go")
+	require.NoError(t, err)
+	quadlet, err := os.ReadFile("testdata/v5.3.1/quadlet.go")
+	require.NoError(t, err)
+
+	older := string(quadlet)
+	for old, replacement := range map[string]string{
+		"\t\tKeyHealthLogDestination:  true,\n": "",
+		`"VolatileTmp" // deprecated`:           `"VolatileTmp"`,
+		`case "keep-id":`:                       `case "keep-id", "nomap":`,
+		"container.Lookup(ContainerGroup, KeySecurityLabelType)": "container.LookupLast(ContainerGroup, " +
+			"KeySecurityLabelType)",
+	} {
+		require.Contains(t, older, old)
+		older = strings.Replace(older, old, replacement, 1)
+	}
+
+	cacheDir := t.TempDir()
+	writeFile(t, filepath.Join(cacheDir, "v5.2.0", "quadlet.go"), older)
+	writeFile(t, filepath.Join(cacheDir, "v5.2.0", "unitfile.go"), string(unitfile))
+	writeFile(t, filepath.Join(cacheDir, "v5.3.1", "quadlet.go"), string(quadlet))
+	writeFile(t, filepath.Join(cacheDir, "v5.3.1", "unitfile.go"), string(unitfile))
+	return cacheDir
+}
+
+func TestRunDiffText(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.NoError(t, runDiff([]string{"--cache-dir", diffCacheDir(t), "v5.2.0", "v5.3.1"}, &out))
+	assert.Equal(t, `Podman v5.2.0 -> v5.3.1
+
+Added keys:
+  [Container] HealthLogDestination
+
+Deprecated keys:
+  [Container] VolatileTmp
+
+Changed keys:
+  [Container] RemapUsers
+    allowed values (container): auto, keep-id, manual, nomap -> auto, keep-id, manual
+  [Container] SecurityLabelType
+    lookup: LookupLast -> Lookup
+  [Kube] RemapUsers
+    allowed values (kube): auto, keep-id, manual, nomap -> auto, keep-id, manual
+  [Pod] RemapUsers
+    allowed values (pod): auto, keep-id, manual, nomap -> auto, keep-id, manual
+`, out.String())
+}
+
+func TestRunDiffJSON(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.NoError(t, runDiff([]string{"--cache-dir", diffCacheDir(t), "--format", "json", "v5.3.1", "v5.2.0"}, &out))
+
+	var diff modelDiff
+	require.NoError(t, json.Unmarshal(out.Bytes(), &diff))
+	assert.Equal(t, "v5.3.1", diff.From)
+	assert.Equal(t, "v5.2.0", diff.To)
+	assert.Empty(t, diff.Added)
+	assert.Equal(t, []keyRef{{Group: "Container", Key: "HealthLogDestination"}}, diff.Removed)
+	// Keys that are no longer deprecated are reported as changed
+	assert.Empty(t, diff.Deprecated)
+	assert.Contains(t, diff.Changed, keyChange{Group: "Container", Key: "VolatileTmp", Changes: []propertyChange{
+		{Property: "deprecated", From: "true", To: "false"},
+	}})
+	assert.Contains(t, out.String(), `"property": "lookup"`)
+}
+
+func TestRunDiffSourceDirs(t *testing.T) {
+	t.Parallel()
+
+	// The checkouts are laid out like Podman's repository and take precedence over the cache
+	cacheDir := diffCacheDir(t)
+	fromSourceDir, toSourceDir := t.TempDir(), t.TempDir()
+	for version, sourceDir := range map[string]string{"v5.2.0": fromSourceDir, "v5.3.1": toSourceDir} {
+		for file, path := range map[string]string{"quadlet.go": quadletFilePath, "unitfile.go": unitfileParserFilePath} {
+			content, err := os.ReadFile(filepath.Join(cacheDir, version, file))
+			require.NoError(t, err)
+			writeFile(t, filepath.Join(sourceDir, filepath.FromSlash(path)), string(content))
+		}
+	}
+
+	var out bytes.Buffer
+	require.NoError(t, runDiff([]string{"--from-source-dir", fromSourceDir, "--to-source-dir", toSourceDir,
+		"--cache-dir", t.TempDir(), "--format", "json", "v5.2.0", "v5.3.1"}, &out))
+
+	var diff modelDiff
+	require.NoError(t, json.Unmarshal(out.Bytes(), &diff))
+	assert.Equal(t, "v5.2.0", diff.From)
+	assert.Equal(t, "v5.3.1", diff.To)
+	assert.Equal(t, []keyRef{{Group: "Container", Key: "HealthLogDestination"}}, diff.Added)
+	assert.Equal(t, []keyRef{{Group: "Container", Key: "VolatileTmp"}}, diff.Deprecated)
+}
+
+func TestRunDiffErrors(t *testing.T) {
+	t.Parallel()
+
+	cacheDir := diffCacheDir(t)
+	var out bytes.Buffer
+	require.EqualError(t, runDiff([]string{"--cache-dir", cacheDir, "v5.3.1"}, &out),
+		"expected 2 Podman versions but got 1")
+	assert.Contains(t, out.String(), "Usage: quadlet-model-gen diff [flags] FROM_VERSION TO_VERSION")
+
+	require.EqualError(t, runDiff([]string{"--cache-dir", cacheDir, "--format", "yaml", "v5.2.0", "v5.3.1"}, &out),
+		"unknown format 'yaml'. Use text or json")
+}
+
+func TestFieldChanges(t *testing.T) {
+	t.Parallel()
+
+	from := field{
+		LookupFunc:   lookupFunc{Name: "LookupLast"},
+		Kind:         kindString,
+		Multiplicity: multiplicitySingle,
+		UnitTypes:    []string{"container"},
+	}
+	to := from
+	to.LookupFunc = lookupFunc{Name: "LookupAll", Multiple: true}
+	to.Multiplicity = multiplicityMultiple
+	to.UnitTypes = []string{"container", "kube"}
+	to.Deprecated = true
+
+	assert.Empty(t, fieldChanges(from, from))
+	assert.Equal(t, []propertyChange{
+		{Property: "lookup", From: "LookupLast", To: "LookupAll"},
+		{Property: "multiplicity", From: multiplicitySingle, To: multiplicityMultiple},
+		{Property: "unit types", From: "container", To: "container, kube"},
+	}, fieldChanges(from, to))
+}
+
+func TestWriteDiffTextWithoutChanges(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.NoError(t, writeDiffText(&out, modelDiff{From: "v5.3.1", To: "v5.3.1"}))
+	assert.Equal(t, "Podman v5.3.1 -> v5.3.1\n\nNo changes\n", out.String())
+}
@@ -0,0 +1,168 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// docsFilePath is the path of the man page documenting the keys relative to the root of podman's repository
+const docsFilePath = "docs/source/markdown/podman-systemd.unit.5.md"
+
+// keyDoc is the documentation of a key in the man page
+type keyDoc struct {
+	Description string
+	// Syntax is the example assignment of the table of the options of the group like AddCapability=CAP
+	Syntax   string
+	Default  string
+	Examples []string
+}
+
+var (
+	// docsGroupHeadingRegexp matches the headings of the sections of the groups like '## Container units [Container]'
+	docsGroupHeadingRegexp = regexp.MustCompile(`^##\s.*\[(\w+)]\s*$`)
+	// docsKeyHeadingRegexp matches the headings of the keys like '### `AddCapability=`'
+	docsKeyHeadingRegexp = regexp.MustCompile("^###\\s+`(\\w+)=`")
+	// docsTableRowRegexp matches the rows of the tables of the options of the groups like '| AddCapability=CAP | ...'
+	docsTableRowRegexp = regexp.MustCompile(`^\|\s*(\w+)=([^|]*?)\s*\|`)
+	docsDefaultRegexp  = regexp.MustCompile("(?i)(?:default(?: value)? is|defaults to) `([^`]+)`")
+	docsLinkRegexp     = regexp.MustCompile(`\[([^]]*)]\([^)]*\)`)
+)
+
+// parseDocs reads the documentation of the keys by group from the markdown source of the podman-systemd.unit man page
+func parseDocs(reader io.Reader) (map[string]map[string]keyDoc, error) {
+	docs := make(map[string]map[string]keyDoc)
+	var group, key string
+	var section []string
+	flush := func() {
+		if group != "" && key != "" {
+			doc := parseKeyDoc(section)
+			doc.Syntax = docs[group][key].Syntax
+			docs[group][key] = doc
+		}
+		key, section = "", nil
+	}
+
+	inCodeBlock := false
+	scanner := bufio.NewScanner(reader)
+	for scanner.Scan() {
+		line := scanner.Text()
+		// Lines of code blocks like comments can look like headings
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			inCodeBlock = !inCodeBlock
+		} else if inCodeBlock {
+			if key != "" {
+				section = append(section, line)
+			}
+			continue
+		}
+
+		switch {
+		case docsGroupHeadingRegexp.MatchString(line):
+			flush()
+			group = docsGroupHeadingRegexp.FindStringSubmatch(line)[1]
+			if docs[group] == nil {
+				docs[group] = make(map[string]keyDoc)
+			}
+		case strings.HasPrefix(line, "### "):
+			flush()
+			if matches := docsKeyHeadingRegexp.FindStringSubmatch(line); matches != nil {
+				key = matches[1]
+			}
+		case strings.HasPrefix(line, "#"):
+			flush()
+			group = ""
+		case group != "" && key == "" && docsTableRowRegexp.MatchString(line):
+			matches := docsTableRowRegexp.FindStringSubmatch(line)
+			doc := docs[group][matches[1]]
+			doc.Syntax = matches[1] + "=" + matches[2]
+			docs[group][matches[1]] = doc
+		case key != "":
+			section = append(section, line)
+		}
+	}
+	flush()
+
+	return docs, scanner.Err()
+}
+
+// parseKeyDoc reads the description from the first paragraph of the section of a key, the default value from a
+// sentence like 'The default is `value`' and the examples from its code blocks
+func parseKeyDoc(section []string) keyDoc {
+	var doc keyDoc
+	var paragraph, example []string
+	inCodeBlock := false
+	for _, line := range section {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case strings.HasPrefix(trimmed, "```"):
+			if inCodeBlock && len(example) > 0 {
+				doc.Examples = append(doc.Examples, strings.Join(example, "\n"))
+			}
+			inCodeBlock, example = !inCodeBlock, nil
+		case inCodeBlock:
+			if trimmed != "" {
+				example = append(example, trimmed)
+			}
+		case trimmed == "":
+			if doc.Description == "" && len(paragraph) > 0 {
+				doc.Description = strings.Join(paragraph, " ")
+			}
+		case doc.Description == "":
+			paragraph = append(paragraph, docsLinkRegexp.ReplaceAllString(trimmed, "$1"))
+		}
+
+		if doc.Default == "" && !inCodeBlock {
+			if matches := docsDefaultRegexp.FindStringSubmatch(line); matches != nil {
+				doc.Default = matches[1]
+			}
+		}
+	}
+
+	if doc.Description == "" {
+		doc.Description = strings.Join(paragraph, " ")
+	}
+	return doc
+}
+
+// setFieldsDocs attaches the documentation of every key to its field
+func setFieldsDocs(fieldsByGroup map[string][]field, docs map[string]map[string]keyDoc) {
+	for group, fields := range fieldsByGroup {
+		for i, field := range fields {
+			if doc, ok := docs[group][field.Key]; ok {
+				fields[i].Doc = &doc
+			}
+		}
+	}
+}
+
+// docsMismatches lists the keys that are documented but not supported and the other way round. Only the groups
+// documented in the man page are compared.
+func docsMismatches(fieldsByGroup map[string][]field, docs map[string]map[string]keyDoc) []string {
+	mismatches := make([]string, 0)
+	for _, group := range slices.Sorted(maps.Keys(docs)) {
+		supported := make(map[string]bool, len(fieldsByGroup[group]))
+		for _, field := range fieldsByGroup[group] {
+			supported[field.Key] = true
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(docs[group])) {
+			if !supported[key] {
+				mismatches = append(mismatches, fmt.Sprintf("key '%s' is documented in group '%s' but not supported",
+					key, group))
+			}
+		}
+
+		for _, key := range slices.Sorted(maps.Keys(supported)) {
+			if _, ok := docs[group][key]; !ok {
+				mismatches = append(mismatches, fmt.Sprintf("key '%s' is supported in group '%s' but not documented",
+					key, group))
+			}
+		}
+	}
+	return mismatches
+}
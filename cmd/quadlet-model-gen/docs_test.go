@@ -0,0 +1,84 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const docsTestFile = "testdata/v5.3.1/podman-systemd.unit.5.md"
+
+func TestParseDocs(t *testing.T) {
+	t.Parallel()
+
+	file, err := os.Open(docsTestFile)
+	require.NoError(t, err)
+	defer file.Close()
+
+	docs, err := parseDocs(file)
+	require.NoError(t, err)
+
+	// Headings of sections other than groups like '## Service Type' and code blocks are not parsed as keys
+	assert.Len(t, docs, 3)
+	assert.Len(t, docs["Container"], 4)
+
+	assert.Equal(t, keyDoc{
+		Description: "Add these capabilities, in addition to the default Podman capability set, to the container.",
+		Syntax:      "AddCapability=CAP",
+		Examples:    []string{"AddCapability=CAP_DAC_OVERRIDE CAP_IPC_OWNER"},
+	}, docs["Container"]["AddCapability"])
+	assert.Equal(t, "Indicates whether the container will be auto-updated (podman-auto-update(1)). "+
+		"The following values are supported:", docs["Container"]["AutoUpdate"].Description)
+	assert.Equal(t, "The image to run in the container. It is recommended to use a fully qualified image name rather "+
+		"than a short name, both for performance and robustness reasons.", docs["Container"]["Image"].Description)
+	assert.Equal(t, "missing", docs["Container"]["Pull"].Default)
+	assert.Equal(t, "Pull=never", docs["Container"]["Pull"].Syntax)
+	assert.Equal(t, keyDoc{
+		Description: "The name of the Podman pod.",
+		Syntax:      "PodName=name",
+		Default:     "systemd-%N",
+	}, docs["Pod"]["PodName"])
+	assert.Equal(t, "true", docs["Quadlet"]["DefaultDependencies"].Default)
+}
+
+func TestSetFieldsDocs(t *testing.T) {
+	t.Parallel()
+
+	fieldsByGroup := map[string][]field{"Pod": {{Key: "PodName"}, {Key: "Network"}}}
+	setFieldsDocs(fieldsByGroup, map[string]map[string]keyDoc{"Pod": {"PodName": {Syntax: "PodName=name"}}})
+	assert.Equal(t, &keyDoc{Syntax: "PodName=name"}, fieldsByGroup["Pod"][0].Doc)
+	assert.Nil(t, fieldsByGroup["Pod"][1].Doc)
+}
+
+func TestDocsMismatches(t *testing.T) {
+	t.Parallel()
+
+	fieldsByGroup := map[string][]field{
+		"Pod":     {{Key: "PodName"}, {Key: "Network"}},
+		"Network": {{Key: "Subnet"}},
+	}
+	docs := map[string]map[string]keyDoc{"Pod": {"PodName": {}, "Removed": {}}}
+
+	// Groups that are not documented are not compared
+	assert.Equal(t, []string{
+		"key 'Removed' is documented in group 'Pod' but not supported",
+		"key 'Network' is supported in group 'Pod' but not documented",
+	}, docsMismatches(fieldsByGroup, docs))
+}
+
+func TestResolveDocsFile(t *testing.T) {
+	t.Parallel()
+
+	sourceDir := t.TempDir()
+	assert.Empty(t, resolveDocsFile("", sourceDir))
+	assert.Empty(t, resolveDocsFile("", ""))
+
+	writeFile(t, filepath.Join(sourceDir, docsFilePath), "% podman-systemd.unit 5")
+	assert.Equal(t, filepath.Join(sourceDir, docsFilePath), resolveDocsFile("", sourceDir))
+
+	// The explicit file takes precedence over the source directory
+	assert.Equal(t, docsTestFile, resolveDocsFile(docsTestFile, sourceDir))
+}
@@ -0,0 +1,249 @@
+package main
+
+import (
+	"go/ast"
+	"go/constant"
+	"go/token"
+	"go/types"
+)
+
+// env binds the parameters of a function to the arguments of the call being followed
+type env struct {
+	args   map[*types.Var]ast.Expr
+	caller *env
+}
+
+// arg returns the argument bound to a parameter of the function along with the environment of the caller
+func (e *env) arg(param *types.Var) (boundExpr, bool) {
+	if e == nil {
+		return boundExpr{}, false
+	}
+
+	arg, ok := e.args[param]
+	return boundExpr{expr: arg, env: e.caller}, ok
+}
+
+// boundExpr is an expression along with the environment of the function it is evaluated in
+type boundExpr struct {
+	expr ast.Expr
+	env  *env
+}
+
+// constString is the value of a string constant expression along with the constant it names if any
+type constString struct {
+	value    string
+	constant *types.Const
+}
+
+// valueFlow follows the values of the variables and of the parameters of a package to the expressions they are
+// assigned with like constants and composite literals
+type valueFlow struct {
+	info  *types.Info
+	scope *types.Scope
+	// assigned are the expressions assigned to every variable
+	assigned map[*types.Var][]ast.Expr
+	// rangedKeys and rangedValues are the expressions ranged over by the keys and the values of range statements
+	rangedKeys   map[*types.Var]ast.Expr
+	rangedValues map[*types.Var]ast.Expr
+}
+
+func newValueFlow(pkg quadletPackage) valueFlow {
+	flow := valueFlow{
+		info:         pkg.info,
+		scope:        pkg.scope,
+		assigned:     make(map[*types.Var][]ast.Expr),
+		rangedKeys:   make(map[*types.Var]ast.Expr),
+		rangedValues: make(map[*types.Var]ast.Expr),
+	}
+
+	assign := func(lhs ast.Expr, rhs ast.Expr) {
+		if variable := flow.variable(lhs); variable != nil {
+			flow.assigned[variable] = append(flow.assigned[variable], rhs)
+		}
+	}
+
+	for _, file := range pkg.files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.AssignStmt:
+				if len(n.Lhs) == len(n.Rhs) && (n.Tok == token.ASSIGN || n.Tok == token.DEFINE) {
+					for i, lhs := range n.Lhs {
+						assign(lhs, n.Rhs[i])
+					}
+				}
+			case *ast.ValueSpec:
+				if len(n.Names) == len(n.Values) {
+					for i, name := range n.Names {
+						assign(name, n.Values[i])
+					}
+				}
+			case *ast.RangeStmt:
+				if variable := flow.variable(n.Key); variable != nil {
+					flow.rangedKeys[variable] = n.X
+				}
+				if variable := flow.variable(n.Value); variable != nil {
+					flow.rangedValues[variable] = n.X
+				}
+			}
+			return true
+		})
+	}
+	return flow
+}
+
+func (f valueFlow) variable(expr ast.Expr) *types.Var {
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return nil
+	}
+
+	variable, _ := f.info.ObjectOf(ident).(*types.Var)
+	return variable
+}
+
+// strings returns the string constants that an expression can evaluate to
+func (f valueFlow) strings(expr ast.Expr, e *env) []constString {
+	values := make([]constString, 0)
+	for _, value := range f.values(expr, e, make(map[*types.Var]bool)) {
+		tv := f.info.Types[value.expr]
+		if tv.Value == nil || tv.Value.Kind() != constant.String {
+			continue
+		}
+
+		values = append(values, constString{value: constant.StringVal(tv.Value), constant: f.constant(value.expr)})
+	}
+	return values
+}
+
+// literals returns the composite literals that an expression can evaluate to
+func (f valueFlow) literals(expr ast.Expr, e *env, visited map[*types.Var]bool) []boundExpr {
+	literals := make([]boundExpr, 0)
+	for _, value := range f.values(expr, e, visited) {
+		if _, ok := value.expr.(*ast.CompositeLit); ok {
+			literals = append(literals, value)
+		}
+	}
+	return literals
+}
+
+// values follows an expression through the variables, the parameters bound by e, the range statements, the indexes
+// and the fields of composite literals. The expressions that cannot be followed like constants are returned.
+func (f valueFlow) values(expr ast.Expr, e *env, visited map[*types.Var]bool) []boundExpr {
+	expr = ast.Unparen(expr)
+	if unary, ok := expr.(*ast.UnaryExpr); ok && unary.Op == token.AND {
+		expr = unary.X
+	}
+
+	if tv, ok := f.info.Types[expr]; ok && tv.Value != nil {
+		return []boundExpr{{expr: expr, env: e}}
+	}
+
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		variable, ok := f.info.Uses[expr].(*types.Var)
+		if !ok || visited[variable] {
+			return nil
+		}
+		visited[variable] = true
+		return f.variableValues(variable, e, visited)
+	case *ast.IndexExpr:
+		return f.indexValues(expr, e, visited)
+	case *ast.SelectorExpr:
+		values := make([]boundExpr, 0)
+		for _, literal := range f.literals(expr.X, e, visited) {
+			for _, elt := range literal.expr.(*ast.CompositeLit).Elts {
+				kv, ok := elt.(*ast.KeyValueExpr)
+				if !ok {
+					continue
+				}
+				if key, ok := kv.Key.(*ast.Ident); ok && key.Name == expr.Sel.Name {
+					values = append(values, f.values(kv.Value, literal.env, visited)...)
+				}
+			}
+		}
+		return values
+	}
+	return []boundExpr{{expr: expr, env: e}}
+}
+
+func (f valueFlow) variableValues(variable *types.Var, e *env, visited map[*types.Var]bool) []boundExpr {
+	values := make([]boundExpr, 0)
+	if arg, ok := e.arg(variable); ok {
+		values = append(values, f.values(arg.expr, arg.env, visited)...)
+	}
+
+	// The package variables are evaluated outside of any call
+	variableEnv := e
+	if variable.Parent() == f.scope {
+		variableEnv = nil
+	}
+
+	for _, assigned := range f.assigned[variable] {
+		values = append(values, f.values(assigned, variableEnv, visited)...)
+	}
+
+	if ranged, ok := f.rangedKeys[variable]; ok {
+		for _, literal := range f.literals(ranged, variableEnv, visited) {
+			for _, elt := range literal.expr.(*ast.CompositeLit).Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					values = append(values, f.values(kv.Key, literal.env, visited)...)
+				}
+			}
+		}
+	}
+
+	if ranged, ok := f.rangedValues[variable]; ok {
+		for _, literal := range f.literals(ranged, variableEnv, visited) {
+			for _, elt := range literal.expr.(*ast.CompositeLit).Elts {
+				if kv, ok := elt.(*ast.KeyValueExpr); ok {
+					elt = kv.Value
+				}
+				values = append(values, f.values(elt, literal.env, visited)...)
+			}
+		}
+	}
+	return values
+}
+
+// indexValues follows the elements of the composite literals indexed by a constant like 'keyArg[0]'
+func (f valueFlow) indexValues(expr *ast.IndexExpr, e *env, visited map[*types.Var]bool) []boundExpr {
+	index := f.info.Types[expr.Index].Value
+	if index == nil {
+		return []boundExpr{{expr: expr, env: e}}
+	}
+
+	values := make([]boundExpr, 0)
+	for _, literal := range f.literals(expr.X, e, visited) {
+		for i, elt := range literal.expr.(*ast.CompositeLit).Elts {
+			if kv, ok := elt.(*ast.KeyValueExpr); ok {
+				key := f.info.Types[kv.Key].Value
+				if key != nil && key.Kind() == index.Kind() && constant.Compare(key, token.EQL, index) {
+					values = append(values, f.values(kv.Value, literal.env, visited)...)
+				}
+				continue
+			}
+
+			if index.Kind() != constant.Int {
+				continue
+			}
+			if position, ok := constant.Int64Val(index); ok && int64(i) == position {
+				values = append(values, f.values(elt, literal.env, visited)...)
+			}
+		}
+	}
+	return values
+}
+
+// constant returns the constant named by an expression like KeyImage
+func (f valueFlow) constant(expr ast.Expr) *types.Const {
+	switch expr := expr.(type) {
+	case *ast.Ident:
+		constant, _ := f.info.Uses[expr].(*types.Const)
+		return constant
+	case *ast.SelectorExpr:
+		constant, _ := f.info.Uses[expr.Sel].(*types.Const)
+		return constant
+	default:
+		return nil
+	}
+}
@@ -4,9 +4,17 @@ import (
 	"bytes"
 	"fmt"
 	"go/format"
+	"go/parser"
+	"go/token"
+	"maps"
 	"os"
 	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
+
+	"github.com/AhmedMoalla/quadlet-lint/pkg/utils"
 )
 
 const (
@@ -15,7 +23,15 @@ const (
 	generatedFilesPerm     = 0777
 )
 
-func generateSourceFiles(data sourceFileData) error {
+var (
+	podmanVersionRegexp  = regexp.MustCompile(`^v\d+\.\d+\.\d+(-[0-9A-Za-z.]+)?$`)
+	versionPackageRegexp = regexp.MustCompile(`^v\d+_\d+_\d+(_[0-9A-Za-z_]+)?$`)
+)
+
+// generateSourceFiles generates the model in the generated directory and the package of the Podman version in one of
+// its subdirectories. Only the package of the version is generated when versionedOnly is true which allows the model
+// to support several versions.
+func generateSourceFiles(data sourceFileData, versionedOnly bool) error {
 	workingDir, err := os.Getwd()
 	if err != nil {
 		panic(err)
@@ -27,7 +43,42 @@ func generateSourceFiles(data sourceFileData) error {
 		return err
 	}
 
-	err = generateFile(outputDir, data, "groups.go", groupsFile)
+	versionPackage, err := versionPackageName(data.podmanVersion)
+	if err != nil {
+		return err
+	}
+
+	err = generateFile(outputDir, data, versionPackage+"/fields.go", versionFieldsFile(versionPackage))
+	if err != nil {
+		return err
+	}
+
+	versionPackages, err := listVersionPackages(outputDir)
+	if err != nil {
+		return err
+	}
+
+	err = generateFile(outputDir, data, "versions.go", versionsFile(versionPackages))
+	if err != nil {
+		return err
+	}
+
+	if versionedOnly {
+		return nil
+	}
+
+	fieldsByVersion, err := readVersionFields(outputDir, versionPackages)
+	if err != nil {
+		return err
+	}
+	computeSince(data.fieldsByGroup, fieldsByVersion)
+
+	err = generateFile(outputDir, data, "groups.go", groupsFile("model", baseModelPackageName))
+	if err != nil {
+		return err
+	}
+
+	err = generateFile(outputDir, data, "documented.go", documentedFieldFile)
 	if err != nil {
 		return err
 	}
@@ -46,11 +97,92 @@ func generateSourceFiles(data sourceFileData) error {
 		return err
 	}
 
+	err = generateFile(outputDir, data, "constraints/constraints.go", constraintsFile)
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
+// versionPackageName returns the name of the package of a Podman version. For v5.3.1, it is v5_3_1.
+func versionPackageName(version string) (string, error) {
+	if !podmanVersionRegexp.MatchString(version) {
+		return "", fmt.Errorf("podman version '%s' is not a tag like v5.3.1", version)
+	}
+
+	return strings.NewReplacer(".", "_", "-", "_").Replace(version), nil
+}
+
+// listVersionPackages returns the packages of the Podman versions already generated in outputDir
+func listVersionPackages(outputDir string) ([]string, error) {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil {
+		return nil, err
+	}
+
+	packages := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() && versionPackageRegexp.MatchString(entry.Name()) {
+			packages = append(packages, entry.Name())
+		}
+	}
+	return packages, nil
+}
+
+// readVersionFields reads the keys of every group supported by the Podman versions generated in outputDir by version
+func readVersionFields(outputDir string, versionPackages []string) (map[string]map[string]map[string]bool, error) {
+	fieldsByVersion := make(map[string]map[string]map[string]bool, len(versionPackages))
+	for _, versionPackage := range versionPackages {
+		path := filepath.Join(outputDir, versionPackage, "fields.go")
+		parsed, err := parser.ParseFile(token.NewFileSet(), path, nil, parser.SkipObjectResolution)
+		if err != nil {
+			return nil, err
+		}
+
+		version, fields := inspectVersionFieldsFile(parsed)
+		if version == "" {
+			return nil, fmt.Errorf("could not find the Podman version of %s", path)
+		}
+		fieldsByVersion[version] = fields
+	}
+	return fieldsByVersion, nil
+}
+
+// computeSince sets the first version supporting each field among the generated versions. It is left empty for the
+// fields supported by the oldest version because they may have been supported long before it.
+func computeSince(fieldsByGroup map[string][]field, fieldsByVersion map[string]map[string]map[string]bool) {
+	versions := slices.SortedFunc(maps.Keys(fieldsByVersion), utils.CompareVersions)
+	if len(versions) == 0 {
+		return
+	}
+
+	for group, fields := range fieldsByGroup {
+		for i, field := range fields {
+			if fieldsByVersion[versions[0]][group][field.Key] {
+				continue
+			}
+
+			for _, version := range versions[1:] {
+				if fieldsByVersion[version][group][field.Key] {
+					fields[i].Since = version
+					break
+				}
+			}
+		}
+	}
+}
+
 type FileGenerator = func(*bytes.Buffer, sourceFileData)
 
+// generatedMarker tells that a file is generated along with the version of the sources it is generated from
+func (d sourceFileData) generatedMarker() string {
+	if d.systemdVersion != "" {
+		return fmt.Sprintf(systemdGeneratedMarkerComment, d.systemdVersion)
+	}
+	return fmt.Sprintf(generatedMarkerComment, d.podmanVersion)
+}
+
 func generateFile(outputDir string, data sourceFileData, filename string, generateFileContent FileGenerator) error {
 	dir, filename := filepath.Split(filename)
 	fileDir := filepath.Join(outputDir, dir)
@@ -68,7 +200,7 @@ func generateFile(outputDir string, data sourceFileData, filename string, genera
 	}
 
 	sb := bytes.Buffer{}
-	sb.WriteString(fmt.Sprintf(generatedMarkerComment, *podmanVersion))
+	sb.WriteString(data.generatedMarker())
 	generateFileContent(&sb, data)
 
 	formatted, err := format.Source(sb.Bytes())
@@ -84,30 +216,49 @@ func generateFile(outputDir string, data sourceFileData, filename string, genera
 	return nil
 }
 
-func groupsFile(b *bytes.Buffer, data sourceFileData) {
-	fieldsByGroup := data.fieldsByGroup
-	b.WriteString("package model\n\n")
-	b.WriteString("import (\n")
-	for group := range fieldsByGroup {
-		b.WriteString(fmt.Sprintf("\t\"%s/%s\"\n", baseModelPackageName, strings.ToLower(group)))
-	}
-	b.WriteString("\tM \"github.com/AhmedMoalla/quadlet-lint/pkg/model\"\n")
-	b.WriteString(")\n\n")
+// groupsFile declares the Groups and the Fields of every group of a model. The package of each group is a
+// subpackage of packagePath.
+func groupsFile(packageName, packagePath string) FileGenerator {
+	return func(b *bytes.Buffer, data sourceFileData) {
+		groups := slices.Sorted(maps.Keys(data.fieldsByGroup))
+		b.WriteString(fmt.Sprintf("package %s\n\n", packageName))
+		b.WriteString("import (\n")
+		for _, group := range groups {
+			b.WriteString(fmt.Sprintf("\t\"%s/%s\"\n", packagePath, strings.ToLower(group)))
+		}
+		b.WriteString("\tM \"github.com/AhmedMoalla/quadlet-lint/pkg/model\"\n")
+		b.WriteString(")\n\n")
 
-	b.WriteString("type Groups struct {\n")
-	for group := range fieldsByGroup {
-		b.WriteString(fmt.Sprintf("\t%s %s.G%s\n", group, strings.ToLower(group), group))
-	}
-	b.WriteString("}\n\n")
+		b.WriteString("type Groups struct {\n")
+		for _, group := range groups {
+			b.WriteString(fmt.Sprintf("\t%s %s.G%s\n", group, strings.ToLower(group), group))
+		}
+		b.WriteString("}\n\n")
 
-	b.WriteString("var Fields =  map[string]map[string]M.Field{\n")
-	for group, fields := range fieldsByGroup {
-		b.WriteString(fmt.Sprintf("\t\"%s\": {\n", group))
-		for _, field := range fields {
-			b.WriteString(fmt.Sprintf("\t\t\"%s\": %s.%s,\n", field.Key, strings.ToLower(group), field.Key))
+		b.WriteString("var Fields =  map[string]map[string]M.Field{\n")
+		for _, group := range groups {
+			b.WriteString(fmt.Sprintf("\t\"%s\": {\n", group))
+			for _, field := range data.fieldsByGroup[group] {
+				b.WriteString(fmt.Sprintf("\t\t\"%s\": %s.%s,\n", field.Key, strings.ToLower(group), field.Key))
+			}
+			b.WriteString("\t},\n")
 		}
-		b.WriteString("\t},\n")
+		b.WriteString("}\n")
 	}
+}
+
+// documentedFieldFile declares DocumentedField which gives the documentation of the model to the fields of the versions
+func documentedFieldFile(b *bytes.Buffer, _ sourceFileData) {
+	b.WriteString("package model\n\n")
+	b.WriteString("import M \"github.com/AhmedMoalla/quadlet-lint/pkg/model\"\n\n")
+	b.WriteString("// DocumentedField returns the field of the model having the group and the key of field. The fields of the\n")
+	b.WriteString("// Podman versions are generated without their documentation so it is taken from the model which is generated\n")
+	b.WriteString("// from the newest version. field is returned as is when its key does not exist in the model.\n")
+	b.WriteString("func DocumentedField(field M.Field) M.Field {\n")
+	b.WriteString("\tif documented, ok := Fields[field.Group][field.Key]; ok {\n")
+	b.WriteString("\t\treturn documented\n")
+	b.WriteString("\t}\n")
+	b.WriteString("\treturn field\n")
 	b.WriteString("}\n")
 }
 
@@ -131,14 +282,66 @@ func groupFile(group string) FileGenerator {
 
 		b.WriteString("var (\n")
 		for _, field := range fieldsByGroup[group] {
-			fieldStr := fmt.Sprintf("M.Field{Group: \"%s\", Key: \"%s\", LookupFunc: lookup.%s }",
-				field.Group, field.Key, field.LookupFunc.Name)
+			fieldStr := fmt.Sprintf("M.Field{Group: \"%s\", Key: \"%s\", LookupFunc: lookup.%s%s%s }",
+				field.Group, field.Key, field.LookupFunc.Name, fieldMetadata(field), fieldDoc(field))
 			b.WriteString(fmt.Sprintf("\t%s = %s\n", field.Key, fieldStr))
 		}
 		b.WriteString(")\n")
 	}
 }
 
+// fieldMetadata returns the optional fields of the M.Field literal of a field that are set
+func fieldMetadata(field field) string {
+	var b strings.Builder
+	if field.Kind != "" {
+		b.WriteString(fmt.Sprintf(", Kind: %q", field.Kind))
+	}
+	if field.Multiplicity != "" {
+		b.WriteString(fmt.Sprintf(", Multiplicity: %q", field.Multiplicity))
+	}
+	if len(field.UnitTypes) > 0 {
+		types := utils.MapSlice(field.UnitTypes, strconv.Quote)
+		b.WriteString(fmt.Sprintf(", UnitTypes: []string{%s}", strings.Join(types, ", ")))
+	}
+	if field.SpecifierPath {
+		b.WriteString(", SpecifierPath: true")
+	}
+	if field.Since != "" {
+		b.WriteString(fmt.Sprintf(", Since: %q", field.Since))
+	}
+	if field.Deprecated {
+		b.WriteString(", Deprecated: true")
+	}
+	if field.Replacement != "" {
+		b.WriteString(fmt.Sprintf(", Replacement: %q", field.Replacement))
+	}
+	return b.String()
+}
+
+// fieldDoc returns the documentation field of the M.Field literal of a field when it is documented. It is only
+// generated in the model to keep the packages of the versions small.
+func fieldDoc(field field) string {
+	if field.Doc == nil {
+		return ""
+	}
+
+	doc := *field.Doc
+	var b strings.Builder
+	b.WriteString(fmt.Sprintf(", Doc: &M.FieldDoc{Description: %q", doc.Description))
+	if doc.Syntax != "" {
+		b.WriteString(fmt.Sprintf(", Syntax: %q", doc.Syntax))
+	}
+	if doc.Default != "" {
+		b.WriteString(fmt.Sprintf(", Default: %q", doc.Default))
+	}
+	if len(doc.Examples) > 0 {
+		examples := utils.MapSlice(doc.Examples, strconv.Quote)
+		b.WriteString(fmt.Sprintf(", Examples: []string{%s}", strings.Join(examples, ", ")))
+	}
+	b.WriteString("}")
+	return b.String()
+}
+
 func lookupFuncFile(b *bytes.Buffer, data sourceFileData) {
 	lookupFuncs := data.lookupFuncs
 	b.WriteString("package lookup\n\n")
@@ -154,3 +357,95 @@ func lookupFuncFile(b *bytes.Buffer, data sourceFileData) {
 	}
 	b.WriteString(")\n")
 }
+
+// constraintsFile declares the rules derived from the constraints enforced by Quadlet by unit type
+func constraintsFile(b *bytes.Buffer, data sourceFileData) {
+	groups := make(map[string]bool)
+	for _, constraintsByGroup := range data.constraints {
+		for group := range constraintsByGroup {
+			groups[group] = true
+		}
+	}
+
+	b.WriteString("package constraints\n\n")
+	b.WriteString("import (\n")
+	b.WriteString(fmt.Sprintf("\tmodel \"%s\"\n", baseModelPackageName))
+	for _, group := range slices.Sorted(maps.Keys(groups)) {
+		b.WriteString(fmt.Sprintf("\t\"%s/%s\"\n", baseModelPackageName, strings.ToLower(group)))
+	}
+	b.WriteString("\tR \"github.com/AhmedMoalla/quadlet-lint/pkg/validator/rules\"\n")
+	b.WriteString(")\n\n")
+
+	b.WriteString("// Rules are the allowed values and dependencies of the keys checked by Quadlet by unit type\n")
+	b.WriteString("var Rules = map[string]model.Groups{\n")
+	for _, unitType := range slices.Sorted(maps.Keys(data.constraints)) {
+		b.WriteString(fmt.Sprintf("\t\"%s\": {\n", unitType))
+		constraintsByGroup := data.constraints[unitType]
+		for _, group := range slices.Sorted(maps.Keys(constraintsByGroup)) {
+			pkg := strings.ToLower(group)
+			b.WriteString(fmt.Sprintf("\t\t%s: %s.G%s{\n", group, pkg, group))
+			constraintsByKey := constraintsByGroup[group]
+			for _, key := range slices.Sorted(maps.Keys(constraintsByKey)) {
+				b.WriteString(fmt.Sprintf("\t\t\t%s: R.Rules(%s),\n", key, constraintRules(pkg, constraintsByKey[key])))
+			}
+			b.WriteString("\t\t},\n")
+		}
+		b.WriteString("\t},\n")
+	}
+	b.WriteString("}\n")
+}
+
+func constraintRules(pkg string, constraints keyConstraints) string {
+	rules := make([]string, 0, len(constraints.DependsOn)+1)
+	if len(constraints.AllowedValues) > 0 {
+		values := utils.MapSlice(constraints.AllowedValues, strconv.Quote)
+		rules = append(rules, fmt.Sprintf("R.AllowedValues(%s)", strings.Join(values, ", ")))
+	}
+	for _, dependency := range constraints.DependsOn {
+		rules = append(rules, fmt.Sprintf("R.DependsOn(%s.%s)", pkg, dependency))
+	}
+	return strings.Join(rules, ", ")
+}
+
+// versionFieldsFile registers the keys of every group supported by the Podman version
+func versionFieldsFile(versionPackage string) FileGenerator {
+	return func(b *bytes.Buffer, data sourceFileData) {
+		b.WriteString(fmt.Sprintf("package %s\n\n", versionPackage))
+		b.WriteString("import (\n")
+		b.WriteString("\tM \"github.com/AhmedMoalla/quadlet-lint/pkg/model\"\n")
+		b.WriteString(fmt.Sprintf("\t\"%s/lookup\"\n", baseModelPackageName))
+		b.WriteString(")\n\n")
+
+		b.WriteString(fmt.Sprintf("const PodmanVersion = \"%s\"\n\n", data.podmanVersion))
+
+		b.WriteString("var Fields = map[string]map[string]M.Field{\n")
+		for _, group := range slices.Sorted(maps.Keys(data.fieldsByGroup)) {
+			fields := slices.Clone(data.fieldsByGroup[group])
+			slices.SortFunc(fields, func(a, b field) int { return strings.Compare(a.Key, b.Key) })
+
+			b.WriteString(fmt.Sprintf("\t\"%s\": {\n", group))
+			for _, field := range fields {
+				b.WriteString(fmt.Sprintf("\t\t\"%s\": {Group: \"%s\", Key: \"%s\", LookupFunc: lookup.%s%s},\n",
+					field.Key, field.Group, field.Key, field.LookupFunc.Name, fieldMetadata(field)))
+			}
+			b.WriteString("\t},\n")
+		}
+		b.WriteString("}\n\n")
+
+		b.WriteString("func init() {\n")
+		b.WriteString("\tM.RegisterPodmanVersion(PodmanVersion, Fields)\n")
+		b.WriteString("}\n")
+	}
+}
+
+// versionsFile imports the packages of the Podman versions so that they are registered with the model
+func versionsFile(versionPackages []string) FileGenerator {
+	return func(b *bytes.Buffer, _ sourceFileData) {
+		b.WriteString("package model\n\n")
+		b.WriteString("import (\n")
+		for _, versionPackage := range versionPackages {
+			b.WriteString(fmt.Sprintf("\t_ \"%s/%s\"\n", baseModelPackageName, versionPackage))
+		}
+		b.WriteString(")\n")
+	}
+}
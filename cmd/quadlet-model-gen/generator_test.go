@@ -0,0 +1,80 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVersionPackageName(t *testing.T) {
+	t.Parallel()
+
+	name, err := versionPackageName("v5.3.1")
+	require.NoError(t, err)
+	assert.Equal(t, "v5_3_1", name)
+
+	name, err = versionPackageName("v5.4.0-rc1")
+	require.NoError(t, err)
+	assert.Equal(t, "v5_4_0_rc1", name)
+
+	for _, version := range []string{"", "main", "5.3.1", "v5.3"} {
+		_, err = versionPackageName(version)
+		require.Error(t, err, version)
+	}
+}
+
+func TestListVersionPackages(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	for _, dir := range []string{"v4_9_0", "v5_4_0_rc1", "container", "lookup"} {
+		require.NoError(t, os.Mkdir(filepath.Join(outputDir, dir), generatedFilesPerm))
+	}
+	require.NoError(t, os.WriteFile(filepath.Join(outputDir, "v5_3_1"), nil, 0600))
+
+	packages, err := listVersionPackages(outputDir)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"v4_9_0", "v5_4_0_rc1"}, packages)
+}
+
+func TestComputeSince(t *testing.T) {
+	t.Parallel()
+
+	image := field{Group: "Container", Key: "Image", LookupFunc: lookupFunc{Name: "Lookup"}}
+	startWithPod := field{Group: "Container", Key: "StartWithPod", LookupFunc: lookupFunc{Name: "LookupBoolean"}}
+	logDestination := field{Group: "Container", Key: "HealthLogDestination", LookupFunc: lookupFunc{Name: "Lookup"}}
+
+	outputDir := t.TempDir()
+	versions := map[string][]field{
+		"v4.9.0": {image},
+		"v5.0.0": {image, startWithPod},
+		"v5.3.1": {image, startWithPod},
+	}
+	for version, fields := range versions {
+		versionPackage, err := versionPackageName(version)
+		require.NoError(t, err)
+
+		data := sourceFileData{podmanVersion: version, fieldsByGroup: map[string][]field{"Container": fields}}
+		err = generateFile(outputDir, data, versionPackage+"/fields.go", versionFieldsFile(versionPackage))
+		require.NoError(t, err)
+	}
+
+	versionPackages, err := listVersionPackages(outputDir)
+	require.NoError(t, err)
+	fieldsByVersion, err := readVersionFields(outputDir, versionPackages)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]map[string]map[string]bool{
+		"v4.9.0": {"Container": {"Image": true}},
+		"v5.0.0": {"Container": {"Image": true, "StartWithPod": true}},
+		"v5.3.1": {"Container": {"Image": true, "StartWithPod": true}},
+	}, fieldsByVersion)
+
+	fieldsByGroup := map[string][]field{"Container": {image, startWithPod, logDestination}}
+	computeSince(fieldsByGroup, fieldsByVersion)
+	assert.Equal(t, []string{"", "v5.0.0", ""},
+		[]string{fieldsByGroup["Container"][0].Since, fieldsByGroup["Container"][1].Since,
+			fieldsByGroup["Container"][2].Since})
+}
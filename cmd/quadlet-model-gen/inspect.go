@@ -3,312 +3,343 @@ package main
 import (
 	"fmt"
 	"go/ast"
+	goconstant "go/constant"
 	"go/token"
 	"go/types"
+	"maps"
+	"slices"
 	"strconv"
 	"strings"
-
-	"github.com/AhmedMoalla/quadlet-lint/pkg/utils"
 )
 
 type declarations struct {
-	otherConstants    map[string]string
-	keyConstants      map[string]string
-	groupConstants    map[string]string
+	keyConstants map[string]string
+	// deprecatedKeys are the replacement hints of the keys marked with a '// deprecated' comment by their value
+	deprecatedKeys map[string]string
+	groupConstants map[string]string
+	// supportedKeysMaps are the keys of the maps of the supported keys by name of map
 	supportedKeysMaps map[string][]string
+	// supportedKeysGroups are the groups of the maps of the supported keys by name of map
+	supportedKeysGroups map[string]string
 }
 
-type lookupFuncCalls struct {
-	direct      map[lookupFuncArgs]lookupFunc
-	ambiguous   map[lookupFuncArgs]lookupFunc
-	alternative map[lookupFuncArgs]lookupFunc
-}
-
-type lookupFuncArgs struct {
-	group string
-	key   lookupFuncKey
-}
-
-type lookupFuncKey struct {
-	name    string
-	literal bool
-}
-
-var alternativeLookupFuncs = map[string]string{
-	"lookupAndAddString":     "Lookup",
-	"lookupAndAddAllStrings": "LookupAll",
-	"lookupAndAddBoolean":    "LookupBoolean",
-}
-
-type lookupArgIndices struct {
-	group int
-	key   int
+// quadletInspection is what the lookups of the quadlet package tell about its keys
+type quadletInspection struct {
+	declarations declarations
+	// lookupFuncs are the functions looking up every key by group and key
+	lookupFuncs map[lookupBinding]map[string]lookupFunc
+	// unresolved are the positions of the lookups whose group or key could not be resolved
+	unresolved []string
 }
 
-func inspectQuadletSourceFileDeclarations(file *ast.File) declarations {
-	result := declarations{
-		otherConstants:    make(map[string]string),
-		groupConstants:    make(map[string]string, nbGroups),
-		keyConstants:      make(map[string]string, nbConstants),
-		supportedKeysMaps: make(map[string][]string, len(groupByKeyMap)),
+// inspectQuadletPackage follows the lookups of the keys from the functions of the quadlet package that are not called
+// by other functions like the Convert functions. The group and the key of a lookup are resolved in the context of
+// every call leading to it so that the parameters of helpers like lookupAndAddString and the tables of keys like the
+// ones of handleHealth are resolved to constants. The constants used as groups and as keys of lookups are the group
+// and key constants. A map of supported keys is a package variable of type map[string]bool that is given to a
+// function along with a group constant.
+func inspectQuadletPackage(pkg quadletPackage, lookupFuncs map[string]lookupFunc) quadletInspection {
+	walker := lookupWalker{
+		pkg:         pkg,
+		flow:        newValueFlow(pkg),
+		lookupFuncs: lookupFuncs,
+		decls:       make(map[*types.Func]*ast.FuncDecl),
+		groups:      make(map[*types.Const]bool),
+		keys:        make(map[*types.Const]bool),
+		found:       make(map[lookupBinding]map[string]lookupFunc),
+		unresolved:  make(map[token.Pos]bool),
 	}
 
-	for _, decl := range file.Decls {
-		decl, ok := decl.(*ast.GenDecl)
-		if !ok {
-			continue
-		}
-
-		if decl.Tok == token.CONST {
-			for _, spec := range decl.Specs {
-				valueSpec, name := mustGetValueSpecName(spec)
-				value := mustExtractConstantValue(valueSpec, name)
-				switch {
-				case isKeyNameConst(name):
-					result.keyConstants[name] = value
-				case isGroupNameConst(name):
-					result.groupConstants[name] = value
-				default:
-					result.otherConstants[name] = value
+	for _, file := range pkg.files {
+		for _, decl := range file.Decls {
+			if decl, ok := decl.(*ast.FuncDecl); ok && decl.Body != nil {
+				if fn, ok := pkg.info.Defs[decl.Name].(*types.Func); ok {
+					walker.decls[fn] = decl
 				}
 			}
 		}
+	}
 
-		if decl.Tok == token.VAR {
-			for _, spec := range decl.Specs {
-				valueSpec, name := mustGetValueSpecName(spec)
-				if _, ok := groupByKeyMap[name]; !ok {
-					continue
-				}
-				keys := mustExtractSupportedKeysFromMap(valueSpec, name)
-				result.supportedKeysMaps[name] = keys
-			}
+	called := make(map[*types.Func]bool)
+	walker.inspectCalls(func(call *ast.CallExpr, callee *types.Func) { called[callee] = true })
+	for fn, decl := range walker.decls {
+		if !called[fn] {
+			walker.walk(decl, nil, []*types.Func{fn})
 		}
 	}
 
-	return result
-}
-
-func inspectQuadletSourceFileLookupCalls(
-	file *ast.File,
-	declarations declarations,
-	lookupFuncs map[string]lookupFunc,
-) lookupFuncCalls {
-	calls := lookupFuncCalls{
-		direct:      make(map[lookupFuncArgs]lookupFunc),
-		ambiguous:   make(map[lookupFuncArgs]lookupFunc),
-		alternative: make(map[lookupFuncArgs]lookupFunc),
+	inspection := quadletInspection{
+		declarations: walker.declarations(),
+		lookupFuncs:  walker.found,
+		unresolved:   make([]string, 0, len(walker.unresolved)),
+	}
+	for _, pos := range slices.Sorted(maps.Keys(walker.unresolved)) {
+		inspection.unresolved = append(inspection.unresolved, pkg.fset.Position(pos).String())
 	}
+	return inspection
+}
 
-	keyConstants := declarations.keyConstants
-	groupConstants := declarations.groupConstants
+type lookupWalker struct {
+	pkg         quadletPackage
+	flow        valueFlow
+	lookupFuncs map[string]lookupFunc
+	decls       map[*types.Func]*ast.FuncDecl
+	// groups and keys are the constants used as groups and as keys of lookups
+	groups     map[*types.Const]bool
+	keys       map[*types.Const]bool
+	found      map[lookupBinding]map[string]lookupFunc
+	unresolved map[token.Pos]bool
+}
 
-	var parentFunc *ast.FuncDecl
-	ast.Inspect(file, func(n ast.Node) bool {
-		if decl, ok := n.(*ast.FuncDecl); ok {
-			parentFunc = decl
+// inspectCalls runs inspect on every call to a function declared by the package
+func (w *lookupWalker) inspectCalls(inspect func(*ast.CallExpr, *types.Func)) {
+	for _, file := range w.pkg.files {
+		ast.Inspect(file, func(n ast.Node) bool {
+			if call, ok := n.(*ast.CallExpr); ok {
+				if callee := w.callee(call); callee != nil {
+					inspect(call, callee)
+				}
+			}
 			return true
-		}
+		})
+	}
+}
 
+// walk records the lookups of a function and follows the calls to the other functions of the package with their
+// parameters bound to the arguments. The functions already in the stack of calls are not followed again.
+func (w *lookupWalker) walk(decl *ast.FuncDecl, e *env, stack []*types.Func) {
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
 		call, ok := n.(*ast.CallExpr)
-		if !ok || len(call.Args) < 2 {
+		if !ok {
 			return true
 		}
 
-		selector, ok := call.Fun.(*ast.SelectorExpr)
-		if !ok {
-			if alternativeCalls, ok := inspectAlternativeLookupCalls(call, parentFunc, declarations, lookupFuncs); ok {
-				calls.alternative = utils.MergeMaps(calls.alternative, alternativeCalls)
-			}
-
+		if lookupFunc, ok := w.lookupFuncs[w.unitFileMethod(call)]; ok && len(call.Args) >= 2 {
+			w.lookup(call, lookupFunc, e)
 			return true
 		}
 
-		lookupFunc, isLookupFunc := lookupFuncs[selector.Sel.Name]
-		if !isLookupFunc {
+		callee := w.callee(call)
+		if callee == nil || slices.Contains(stack, callee) {
 			return true
 		}
 
-		groupConstName, key := mustGetLookupFuncArgs(call, lookupArgIndices{group: 0, key: 1})
-
-		groupName, okGroup := groupConstants[groupConstName]
-		keyName, okKey := keyConstants[key.name]
-		if okGroup && okKey {
-			calls.direct[lookupFuncArgs{
-				group: groupName,
-				key:   lookupFuncKey{name: keyName},
-			}] = lookupFunc
-		} else {
-			calls.ambiguous[lookupFuncArgs{
-				group: groupConstName,
-				key:   key,
-			}] = lookupFunc
+		params := callee.Type().(*types.Signature).Params()
+		args := make(map[*types.Var]ast.Expr, params.Len())
+		for i := 0; i < params.Len() && i < len(call.Args); i++ {
+			args[params.At(i)] = call.Args[i]
 		}
-
+		w.walk(w.decls[callee], &env{args: args, caller: e}, append(slices.Clip(stack), callee))
 		return true
 	})
+}
 
-	return calls
+func (w *lookupWalker) lookup(call *ast.CallExpr, function lookupFunc, e *env) {
+	groups := w.flow.strings(call.Args[0], e)
+	keys := w.flow.strings(call.Args[1], e)
+	if len(groups) == 0 || len(keys) == 0 {
+		w.unresolved[call.Pos()] = true
+		return
+	}
+
+	for _, group := range groups {
+		w.groups[group.constant] = true
+		for _, key := range keys {
+			w.keys[key.constant] = true
+			binding := lookupBinding{group: group.value, key: key.value}
+			if w.found[binding] == nil {
+				w.found[binding] = make(map[string]lookupFunc)
+			}
+			w.found[binding][function.Name] = function
+		}
+	}
 }
 
-func inspectAlternativeLookupCalls(
-	call *ast.CallExpr,
-	parentFunc *ast.FuncDecl,
-	decls declarations,
-	lookupFuncs map[string]lookupFunc,
-) (map[lookupFuncArgs]lookupFunc, bool) {
-	ident, ok := call.Fun.(*ast.Ident)
+// unitFileMethod returns the name of the method of the units called like 'unit.Lookup' or an empty string
+func (w *lookupWalker) unitFileMethod(call *ast.CallExpr) string {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
 	if !ok {
-		return nil, false
+		return ""
 	}
 
-	_, ok = alternativeLookupFuncs[ident.Name]
-	if !ok {
-		return nil, false
+	selection, ok := w.pkg.info.Selections[selector]
+	if !ok || selection.Kind() != types.MethodVal {
+		return ""
 	}
 
-	groupConstName, keysMap := mustGetLookupFuncArgs(call, lookupArgIndices{group: 1, key: 2}) //nolint:mnd
+	recv := selection.Recv()
+	if pointer, ok := recv.(*types.Pointer); ok {
+		recv = pointer.Elem()
+	}
+	if !types.Identical(recv, w.pkg.unitFile) {
+		return ""
+	}
+	return selector.Sel.Name
+}
 
-	alternative := make(map[lookupFuncArgs]lookupFunc)
-	keyConstNames := mustGetMapKeyFromVariableDefinition(parentFunc.Body.List, keysMap.name)
-	for _, keyConstName := range keyConstNames {
-		alternative[lookupFuncArgs{
-			group: decls.groupConstants[groupConstName],
-			key:   lookupFuncKey{name: decls.keyConstants[keyConstName]},
-		}] = lookupFuncs[alternativeLookupFuncs[ident.Name]]
+// callee returns the function or the method declared by the package that is called
+func (w *lookupWalker) callee(call *ast.CallExpr) *types.Func {
+	var ident *ast.Ident
+	switch fun := ast.Unparen(call.Fun).(type) {
+	case *ast.Ident:
+		ident = fun
+	case *ast.SelectorExpr:
+		ident = fun.Sel
+	default:
+		return nil
 	}
 
-	return alternative, true
+	fn, ok := w.pkg.info.Uses[ident].(*types.Func)
+	if !ok || w.decls[fn.Origin()] == nil {
+		return nil
+	}
+	return fn.Origin()
 }
 
-func mustGetMapKeyFromVariableDefinition(statements []ast.Stmt, name string) []string {
-	for _, statement := range statements {
-		statement, ok := statement.(*ast.AssignStmt)
-		if !ok || statement.Tok != token.ASSIGN && statement.Tok != token.DEFINE {
-			continue
-		}
+func (w *lookupWalker) declarations() declarations {
+	result := declarations{
+		keyConstants:        make(map[string]string),
+		deprecatedKeys:      make(map[string]string),
+		groupConstants:      make(map[string]string),
+		supportedKeysMaps:   make(map[string][]string),
+		supportedKeysGroups: make(map[string]string),
+	}
 
-		var value *ast.CompositeLit
-		for i, variable := range statement.Lhs {
-			ident, ok := variable.(*ast.Ident)
-			if !ok {
-				continue
+	for name, keys := range w.supportedKeysMaps() {
+		result.supportedKeysMaps[name] = make([]string, 0, len(keys))
+		for _, key := range keys {
+			result.supportedKeysMaps[name] = append(result.supportedKeysMaps[name], key.value)
+			w.keys[key.constant] = true
+		}
+	}
+	w.inspectCalls(func(call *ast.CallExpr, _ *types.Func) {
+		if name, group, ok := w.supportedKeysGroup(call, result.supportedKeysMaps); ok {
+			if _, exists := result.supportedKeysGroups[name]; !exists {
+				result.supportedKeysGroups[name] = group
 			}
+		}
+	})
+	for name := range result.supportedKeysMaps {
+		if _, ok := result.supportedKeysGroups[name]; !ok {
+			delete(result.supportedKeysMaps, name)
+		}
+	}
+
+	for constant := range w.groups {
+		if constant != nil {
+			result.groupConstants[constant.Name()] = constantString(constant)
+		}
+	}
+	for constant := range w.keys {
+		if constant != nil {
+			result.keyConstants[constant.Name()] = constantString(constant)
+		}
+	}
 
-			if ident.Name != name {
+	for _, file := range w.pkg.files {
+		for _, decl := range file.Decls {
+			decl, ok := decl.(*ast.GenDecl)
+			if !ok || decl.Tok != token.CONST {
 				continue
 			}
 
-			variableValue := statement.Rhs[i]
-			value, ok = variableValue.(*ast.CompositeLit)
-			if !ok {
-				panic(fmt.Sprintf("expected variable %s to be a composite literal but found type %T instead", name, variableValue))
+			for _, spec := range decl.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok {
+					continue
+				}
+				for _, name := range valueSpec.Names {
+					constant, ok := w.pkg.info.Defs[name].(*types.Const)
+					if !ok || !w.keys[constant] {
+						continue
+					}
+					if replacement, deprecated := deprecationComment(valueSpec); deprecated {
+						result.deprecatedKeys[constantString(constant)] = replacement
+					}
+				}
 			}
-
-			break
 		}
+	}
+	return result
+}
 
-		if value == nil {
+// supportedKeysMaps returns the constant keys of the package variables of type map[string]bool by name of variable
+func (w *lookupWalker) supportedKeysMaps() map[string][]constString {
+	mapType := types.NewMap(types.Typ[types.String], types.Typ[types.Bool])
+	maps := make(map[string][]constString)
+	for _, name := range w.pkg.scope.Names() {
+		variable, ok := w.pkg.scope.Lookup(name).(*types.Var)
+		if !ok || !types.Identical(variable.Type(), mapType) {
 			continue
 		}
 
-		_, isMap := value.Type.(*ast.MapType)
-		if !isMap {
-			panic(fmt.Sprintf("expected variable %s to be a map but found type %T instead", name, value.Type))
-		}
-
-		keys := make([]string, 0, len(value.Elts))
-		for _, elt := range value.Elts {
-			kv, ok := elt.(*ast.KeyValueExpr)
-			if !ok {
-				panic(fmt.Sprintf("expected elements of composite literal of variable %s to be of type "+
-					"*ast.KeyValueExpr but found type %T instead", name, elt))
-			}
-
-			keyConst, ok := kv.Key.(*ast.Ident)
-			if !ok {
-				panic(fmt.Sprintf("expected key of key-value element of composite literal of variable %s to be of type "+
-					"*ast.Ident but found type %T instead", name, kv.Key))
+		for _, assigned := range w.flow.assigned[variable] {
+			for _, literal := range w.flow.literals(assigned, nil, make(map[*types.Var]bool)) {
+				for _, elt := range literal.expr.(*ast.CompositeLit).Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						maps[name] = append(maps[name], w.flow.strings(kv.Key, nil)...)
+					}
+				}
 			}
-
-			keys = append(keys, keyConst.Name)
 		}
-
-		return keys
 	}
-
-	return nil
+	return maps
 }
 
-func mustGetLookupFuncArgs(call *ast.CallExpr, argIndices lookupArgIndices) (string, lookupFuncKey) {
-	groupConst, ok := call.Args[argIndices.group].(*ast.Ident)
-	if !ok {
-		panic(fmt.Sprintf("expected lookup function's 1st argument to be an identifier containing a group name "+
-			"(*ast.Ident) but found %T instead. The parser likely needs to be updated", call.Args[0]))
-	}
+// supportedKeysGroup matches the calls given a single map of supported keys along with a single group constant like
+// 'checkForUnknownKeys(container, ContainerGroup, supportedContainerKeys)'
+func (w *lookupWalker) supportedKeysGroup(call *ast.CallExpr, supportedKeysMaps map[string][]string) (
+	string, string, bool) {
+	var names, groups []string
+	for _, arg := range call.Args {
+		if ident, ok := arg.(*ast.Ident); ok {
+			if variable, ok := w.pkg.info.Uses[ident].(*types.Var); ok && variable.Parent() == w.pkg.scope {
+				if _, ok := supportedKeysMaps[variable.Name()]; ok {
+					names = append(names, variable.Name())
+					continue
+				}
+			}
+		}
 
-	key := lookupFuncKey{}
-	switch arg1 := call.Args[argIndices.key].(type) {
-	case *ast.Ident:
-		key.name = arg1.Name
-	case *ast.BasicLit:
-		if arg1.Kind != token.STRING {
-			panic(fmt.Sprintf("expected the 2nd argument of lookup function %s to be a string litreal "+
-				"but was %T instead. The parser likely needs to be updated", types.ExprString(call.Fun), call.Args[1]))
+		if values := w.flow.strings(arg, nil); len(values) == 1 && w.groups[values[0].constant] {
+			groups = append(groups, values[0].value)
 		}
-		key.name, _ = strconv.Unquote(arg1.Value)
-		key.literal = true
-	case *ast.IndexExpr: // ignore those and look for alternative lookup functions
-	default:
-		panic(fmt.Sprintf("expected the 2nd argument of lookup function %s to be an identifier containing a key name "+
-			"or a string literal but found %T instead. The parser likely needs to be updated",
-			types.ExprString(call.Fun), call.Args[1]))
 	}
-	return groupConst.Name, key
-}
 
-func isKeyNameConst(name string) bool {
-	return strings.HasPrefix(name, "Key")
-}
-
-func isGroupNameConst(name string) bool {
-	return strings.HasSuffix(name, "Group") &&
-		!strings.HasPrefix(name, "X") &&
-		!isKeyNameConst(name)
+	if len(names) != 1 || len(groups) != 1 {
+		return "", "", false
+	}
+	return names[0], groups[0], true
 }
 
-func mustExtractSupportedKeysFromMap(spec *ast.ValueSpec, name string) []string {
-	if len(spec.Values) != 1 {
-		panic(fmt.Sprintf("quadlet.go should only have constants that have a single value. "+
-			"Spec %s has %d values. The parser likely needs to be updated", name, len(spec.Values)))
+func constantString(constant *types.Const) string {
+	if constant.Val().Kind() != goconstant.String {
+		return constant.Val().ExactString()
 	}
+	return goconstant.StringVal(constant.Val())
+}
 
-	value, ok := spec.Values[0].(*ast.CompositeLit)
-	_, isMap := value.Type.(*ast.MapType)
-	if !ok || !isMap {
-		panic(fmt.Sprintf("quadlet.go should only have variables defining maps of supported keys that have map "+
-			"literal values of type *ast.CompositeLit. Spec %s is of type %T with values of type %T. "+
-			"The parser likely needs to be updated", name, value.Type, spec.Values[0]))
+// deprecationComment finds the '// deprecated' comment following a constant like:
+//
+//	KeyRemapUid = "RemapUid" //nolint:stylecheck // deprecated
+//
+// The text following 'deprecated' is returned as a replacement hint like in '// deprecated: use UserNS'.
+func deprecationComment(spec *ast.ValueSpec) (string, bool) {
+	if spec.Comment == nil {
+		return "", false
 	}
 
-	keys := make([]string, 0, len(value.Elts))
-	for _, elt := range value.Elts {
-		kv, ok := elt.(*ast.KeyValueExpr)
-		if !ok {
-			panic(fmt.Sprintf("quadlet.go should only have key-value composite literals should be of type *ast.KeyValueExpr. "+
-				"Spec %s has composite literal of type %T. The parser likely needs to be updated", name, elt))
-		}
+	for _, comment := range spec.Comment.List {
+		for _, part := range strings.Split(comment.Text, "//") {
+			part = strings.TrimSpace(part)
+			if !strings.HasPrefix(strings.ToLower(part), "deprecated") {
+				continue
+			}
 
-		key, ok := kv.Key.(*ast.Ident)
-		if !ok {
-			panic(fmt.Sprintf("quadlet.go should only have keys of key-value composite literals that are of type *ast.Ident. "+
-				"Spec %s has key of type %T. The parser likely needs to be updated", name, kv.Key))
+			return strings.TrimSpace(strings.TrimLeft(part[len("deprecated"):], " :,;-")), true
 		}
-
-		keys = append(keys, key.Name)
 	}
-
-	return keys
+	return "", false
 }
 
 func mustExtractConstantValue(spec *ast.ValueSpec, name string) string {
@@ -331,18 +362,62 @@ func mustExtractConstantValue(spec *ast.ValueSpec, name string) string {
 	return unquoted
 }
 
-func mustGetValueSpecName(spec ast.Spec) (*ast.ValueSpec, string) {
-	valueSpec, ok := spec.(*ast.ValueSpec)
-	if !ok {
-		panic(fmt.Sprintf("quadlet.go should only have constants of type *ast.ValueSpec. "+
-			"Spec %s is of type %T. The parser likely needs to be updated", valueSpec.Names, valueSpec))
+// inspectVersionFieldsFile returns the Podman version and the keys of every group of a file generated for a version
+func inspectVersionFieldsFile(file *ast.File) (string, map[string]map[string]bool) {
+	var version string
+	fields := make(map[string]map[string]bool)
+	for _, decl := range file.Decls {
+		decl, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+
+		for _, spec := range decl.Specs {
+			valueSpec, ok := spec.(*ast.ValueSpec)
+			if !ok || len(valueSpec.Names) != 1 || len(valueSpec.Values) != 1 {
+				continue
+			}
+
+			switch valueSpec.Names[0].Name {
+			case "PodmanVersion":
+				version = mustExtractConstantValue(valueSpec, "PodmanVersion")
+			case "Fields":
+				groups, _ := valueSpec.Values[0].(*ast.CompositeLit)
+				for group, keys := range compositeLitKeys(groups) {
+					fields[group] = make(map[string]bool)
+					for key := range compositeLitKeys(keys) {
+						fields[group][key] = true
+					}
+				}
+			}
+		}
 	}
+	return version, fields
+}
 
-	if len(valueSpec.Names) != 1 {
-		panic(fmt.Sprintf("quadlet.go should only have constants that have a single name. "+
-			"Spec %s has %d names. The parser likely needs to be updated", valueSpec.Names, len(valueSpec.Names)))
+// compositeLitKeys returns the string keys of a map literal along with their values when they are literals too
+func compositeLitKeys(lit *ast.CompositeLit) map[string]*ast.CompositeLit {
+	keys := make(map[string]*ast.CompositeLit)
+	if lit == nil {
+		return keys
 	}
 
-	name := valueSpec.Names[0].Name
-	return valueSpec, name
+	for _, elt := range lit.Elts {
+		kv, ok := elt.(*ast.KeyValueExpr)
+		if !ok {
+			continue
+		}
+
+		key, ok := kv.Key.(*ast.BasicLit)
+		if !ok || key.Kind != token.STRING {
+			continue
+		}
+
+		unquoted, err := strconv.Unquote(key.Value)
+		if err != nil {
+			continue
+		}
+		keys[unquoted], _ = kv.Value.(*ast.CompositeLit)
+	}
+	return keys
 }
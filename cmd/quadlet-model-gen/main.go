@@ -1,68 +1,152 @@
 package main
 
 import (
-	"context"
+	"errors"
 	"flag"
 	"fmt"
-	"io"
-	"net/http"
 	"os"
-	"path/filepath"
-	"strings"
 )
 
 const (
 	podmanVersionFlag   = "podman-version"
 	podmanVersionEnvKey = "PODMAN_VERSION"
-
-	podmanGithubTagsURL        = "https://raw.githubusercontent.com/containers/podman/refs/tags/%s"
-	quadletFileLocation        = podmanGithubTagsURL + "/pkg/systemd/quadlet/quadlet.go"
-	unitfileParserFileLocation = podmanGithubTagsURL + "/pkg/systemd/parser/unitfile.go"
-	baseModelPackageName       = "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
+	sourceDirFlag       = "source-dir"
+	fromSourceDirFlag   = "from-source-dir"
+	toSourceDirFlag     = "to-source-dir"
+	quadletFileFlag     = "quadlet-file"
+	unitfileFileFlag    = "unitfile-file"
+	cacheDirFlag        = "cache-dir"
+	docsFileFlag        = "docs-file"
+	cacheDirEnvKey      = "QUADLET_MODEL_GEN_CACHE_DIR"
+	versionedOnlyFlag   = "versioned-only"
+
+	baseModelPackageName = "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
 )
 
 var (
 	podmanVersion = flag.String(podmanVersionFlag, "", "Podman's tag used to download the source file for code generation")
+	sourceDir     = flag.String(sourceDirFlag, "", "Podman checkout used instead of downloading the source files")
+	quadletFile   = flag.String(quadletFileFlag, "", "quadlet.go source file used instead of downloading it")
+	unitfileFile  = flag.String(unitfileFileFlag, "", "unitfile.go source file used instead of downloading it")
+	cacheDir      = flag.String(cacheDirFlag, "", "Directory where downloaded source files are cached by Podman's tag")
+	docsFile      = flag.String(docsFileFlag, "", "podman-systemd.unit.5.md man page source documenting the keys")
+	versionedOnly = flag.Bool(versionedOnlyFlag, false,
+		"Only generate the package of the Podman version to support it along with the version of the model")
 )
 
+var errNoPodmanVersion = fmt.Errorf("podman version was not provided. "+
+	"Use -%s flag or %s environment variable", podmanVersionFlag, podmanVersionEnvKey)
+
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == diffCommand {
+		if err := runDiff(os.Args[2:], os.Stdout); err != nil && !errors.Is(err, flag.ErrHelp) {
+			exit(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == systemdCommand {
+		if err := runSystemd(os.Args[2:], os.Stdout); err != nil && !errors.Is(err, flag.ErrHelp) {
+			exit(err)
+		}
+		return
+	}
+
 	flag.Parse()
 
-	podmanVersion := getPodmanVersion(*podmanVersion)
+	version := getPodmanVersion(*podmanVersion)
+	if version == "" {
+		exit(errNoPodmanVersion)
+	}
 
-	unitfileParserFile, err := downloadSourceFileFromGithub(unitfileParserFileLocation, podmanVersion)
+	files, err := resolveSourceFiles(sourceOptions{
+		podmanVersion: version,
+		sourceDir:     *sourceDir,
+		quadletFile:   *quadletFile,
+		unitfileFile:  *unitfileFile,
+		cacheDir:      getCacheDir(*cacheDir),
+		baseURL:       podmanGithubTagsURL,
+	})
 	if err != nil {
-		exit(fmt.Errorf("could not download unitfile.go source file: %w", err))
+		exit(err)
 	}
-	defer os.Remove(unitfileParserFile.Name())
+	defer files.remove()
 
-	quadletSourceFile, err := downloadSourceFileFromGithub(quadletFileLocation, podmanVersion)
+	unitfileParserFile, err := os.Open(files.unitfile)
 	if err != nil {
-		exit(fmt.Errorf("could not download quadlet.go source file: %w", err))
+		exit(fmt.Errorf("could not open unitfile.go source file: %w", err))
 	}
-	defer os.Remove(quadletSourceFile.Name())
+	defer unitfileParserFile.Close()
 
-	parseAndGenerateFiles(quadletSourceFile, unitfileParserFile)
+	quadletSourceFile, err := os.Open(files.quadlet)
+	if err != nil {
+		exit(fmt.Errorf("could not open quadlet.go source file: %w", err))
+	}
+	defer quadletSourceFile.Close()
+
+	var docs *os.File
+	if path := resolveDocsFile(*docsFile, *sourceDir); path != "" {
+		docs, err = os.Open(path)
+		if err != nil {
+			exit(fmt.Errorf("could not open man page source file: %w", err))
+		}
+		defer docs.Close()
+	}
+
+	parseAndGenerateFiles(quadletSourceFile, unitfileParserFile, docs, version, *versionedOnly)
 }
 
-func parseAndGenerateFiles(quadletSourceFile, unitfileParserFile *os.File) {
-	lookupFuncs, err := parseUnitFileParserSourceFile(unitfileParserFile)
+// parseAndGenerateFiles parses the source files and generates the model. The keys are documented when docsFile is not
+// nil.
+func parseAndGenerateFiles(
+	quadletSourceFile, unitfileParserFile, docsFile *os.File,
+	version string,
+	versionedOnly bool,
+) {
+	data, err := parseSourceFiles(quadletSourceFile, unitfileParserFile, version)
 	if err != nil {
-		exit(fmt.Errorf("could not parse unitfile parser source file: %w", err))
+		exit(err)
 	}
 
-	fieldsByGroup, err := parseQuadletSourceFile(quadletSourceFile, lookupFuncs)
-	if err != nil {
-		exit(fmt.Errorf("could not parse quadlet source file: %w", err))
+	if docsFile != nil {
+		docs, err := parseDocs(docsFile)
+		if err != nil {
+			exit(fmt.Errorf("could not parse man page source file: %w", err))
+		}
+
+		setFieldsDocs(data.fieldsByGroup, docs)
+		for _, mismatch := range docsMismatches(data.fieldsByGroup, docs) {
+			fmt.Fprintf(os.Stderr, "warning: %s\n", mismatch)
+		}
 	}
 
-	data := sourceFileData{fieldsByGroup: fieldsByGroup, lookupFuncs: lookupFuncs}
-	err = generateSourceFiles(data)
+	err = generateSourceFiles(data, versionedOnly)
 	if err != nil {
 		exit(fmt.Errorf("could not generate source files: %w", err))
 	}
 }
 
+// parseSourceFiles parses the keys, their lookup functions and their constraints from the source files of a version
+func parseSourceFiles(quadletSourceFile, unitfileParserFile *os.File, version string) (sourceFileData, error) {
+	lookupFuncs, err := parseUnitFileParserSourceFile(unitfileParserFile)
+	if err != nil {
+		return sourceFileData{}, fmt.Errorf("could not parse unitfile parser source file: %w", err)
+	}
+
+	fieldsByGroup, constraints, err := parseQuadletPackage(quadletSourceFile.Name(), unitfileParserFile.Name(),
+		lookupFuncs)
+	if err != nil {
+		return sourceFileData{}, fmt.Errorf("could not parse quadlet package: %w", err)
+	}
+
+	return sourceFileData{
+		podmanVersion: version,
+		fieldsByGroup: fieldsByGroup,
+		lookupFuncs:   lookupFuncs,
+		constraints:   constraints,
+	}, nil
+}
+
 func exit(err error) {
 	_, err = fmt.Fprintf(os.Stderr, "%s\n", err.Error())
 	if err != nil {
@@ -82,41 +166,10 @@ func getPodmanVersion(version string) string {
 	return version
 }
 
-func downloadSourceFileFromGithub(location string, version string) (*os.File, error) {
-	if version == "" {
-		return nil, fmt.Errorf("podman version was not provided. "+
-			"Use -%s flag or %s environment variable", podmanVersionFlag, podmanVersionEnvKey)
-	}
-
-	url := fmt.Sprintf(location, version)
-	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
-	if err != nil {
-		return nil, err
-	}
-
-	response, err := http.DefaultClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to download from '%s': %w", url, err)
-	}
-	defer response.Body.Close()
-
-	if response.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("unexpected status '%s' when downloading from '%s'", response.Status, url)
-	}
-
-	fileName := filepath.Base(location)
-	ext := filepath.Ext(fileName)
-	fileName = strings.TrimSuffix(fileName, ext)
-	file, err := os.CreateTemp("", fileName+"-*"+ext)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create temporary file to copy the content of quadlet file: %w", err)
-	}
-	defer file.Close()
-
-	_, err = io.Copy(file, response.Body)
-	if err != nil {
-		return nil, fmt.Errorf("failed to copy file contents: %w", err)
+func getCacheDir(dir string) string {
+	if dir == "" {
+		return os.Getenv(cacheDirEnvKey)
 	}
 
-	return file, nil
+	return dir
 }
@@ -0,0 +1,351 @@
+package main
+
+import (
+	"go/ast"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// Kinds of the values of the keys
+const (
+	kindString    = "string"
+	kindBool      = "bool"
+	kindInt       = "int"
+	kindUint32    = "uint32"
+	kindArgs      = "args"
+	kindKeyValue  = "key-value"
+	kindReference = "reference"
+)
+
+// Multiplicities of the keys
+const (
+	multiplicitySingle   = "single"
+	multiplicityMultiple = "multiple"
+)
+
+// valueKindByLookupFunc are the kinds of the values converted by the lookup functions. The other lookup functions
+// return strings.
+var valueKindByLookupFunc = map[string]string{
+	"LookupBoolean":            kindBool,
+	"LookupBooleanWithDefault": kindBool,
+	"LookupInt":                kindInt,
+	"LookupUint32":             kindUint32,
+	"LookupAllArgs":            kindArgs,
+	"LookupLastArgs":           kindArgs,
+	"LookupAllKeyVal":          kindKeyValue,
+}
+
+const (
+	// unitsInfoMapName is the variable holding the units that can be referenced by name when converting a unit
+	unitsInfoMapName = "unitsInfoMap"
+	// specifierFuncName is the function checking whether a path starts with a systemd specifier
+	specifierFuncName = "startsWithSystemdSpecifier"
+)
+
+type sinkKind int
+
+const (
+	// pathSink is reached by the values that are paths which can start with a systemd specifier
+	pathSink sinkKind = iota
+	// referenceSink is reached by the values that can reference another unit
+	referenceSink
+)
+
+// keyUsage is how Quadlet uses a key when converting units
+type keyUsage struct {
+	unitTypes map[string]bool
+	path      bool
+	reference bool
+}
+
+// usagesByGroup holds the usage of every key by group and key
+type usagesByGroup map[string]map[string]*keyUsage
+
+func (u usagesByGroup) get(group, key string) *keyUsage {
+	if u[group] == nil {
+		u[group] = make(map[string]*keyUsage)
+	}
+	if u[group][key] == nil {
+		u[group][key] = &keyUsage{unitTypes: make(map[string]bool)}
+	}
+	return u[group][key]
+}
+
+// setFieldsMetadata sets the kind, the multiplicity, the unit types and whether the value is a path of every field
+func setFieldsMetadata(fieldsByGroup map[string][]field, usages usagesByGroup) {
+	for group, fields := range fieldsByGroup {
+		for i, field := range fields {
+			kind, ok := valueKindByLookupFunc[field.LookupFunc.Name]
+			if !ok {
+				kind = kindString
+			}
+
+			multiplicity := multiplicitySingle
+			if field.LookupFunc.Multiple && field.LookupFunc.Name != "LookupLastArgs" {
+				multiplicity = multiplicityMultiple
+			}
+
+			if usage := usages[group][field.Key]; usage != nil {
+				fields[i].UnitTypes = slices.Sorted(maps.Keys(usage.unitTypes))
+				fields[i].SpecifierPath = usage.path
+				// The kinds splitting the values are kept because they tell how references are read from the values
+				if usage.reference && kind == kindString {
+					kind = kindReference
+				}
+			}
+
+			fields[i].Kind = kind
+			fields[i].Multiplicity = multiplicity
+		}
+	}
+}
+
+// taint is where a value comes from. It is either a parameter of the function or a looked up key.
+type taint struct {
+	param int
+	key   lookupBinding
+}
+
+// sinkParams are the parameters of the functions whose values reach a sink by function and sink
+type sinkParams map[string]map[sinkKind]map[int]bool
+
+func (s sinkParams) add(funcName string, sink sinkKind, param int) bool {
+	if s[funcName] == nil {
+		s[funcName] = make(map[sinkKind]map[int]bool)
+	}
+	if s[funcName][sink] == nil {
+		s[funcName][sink] = make(map[int]bool)
+	}
+	if s[funcName][sink][param] {
+		return false
+	}
+	s[funcName][sink][param] = true
+	return true
+}
+
+// inspectQuadletSourceFileUsages follows the values of the keys in the Convert functions and in the functions they
+// call. A key is used by a unit type when it is looked up or supported while converting it. Its values are paths when
+// they are checked for systemd specifiers and references when they are looked up in the units that can be referenced.
+func inspectQuadletSourceFileUsages(file *ast.File, declarations declarations) usagesByGroup {
+	funcs := make(map[string]*ast.FuncDecl)
+	for _, decl := range file.Decls {
+		if decl, ok := decl.(*ast.FuncDecl); ok && decl.Recv == nil && decl.Body != nil {
+			funcs[decl.Name.Name] = decl
+		}
+	}
+
+	sinks := inspectSinkParams(funcs)
+	usages := make(usagesByGroup)
+	for name, contexts := range inspectConvertContexts(funcs, declarations) {
+		for context := range contexts {
+			for mapName, keys := range supportedKeysMapsUsedBy(funcs[name], declarations) {
+				for _, key := range keys {
+					usage := usages.get(declarations.supportedKeysGroups[mapName], key)
+					usage.unitTypes[context.unitType] = true
+				}
+			}
+
+			walker := taintWalker{sinks: sinks, declarations: declarations, context: context}
+			walker.walk(funcs[name], func(binding lookupBinding) {
+				usages.get(binding.group, binding.key).unitTypes[context.unitType] = true
+			}, func(sink sinkKind, t taint) {
+				if t.param >= 0 {
+					return
+				}
+
+				usage := usages.get(t.key.group, t.key.key)
+				switch sink {
+				case pathSink:
+					usage.path = true
+				case referenceSink:
+					usage.reference = true
+				}
+			})
+		}
+	}
+	return usages
+}
+
+// supportedKeysMapsUsedBy returns the maps of the supported keys that a function checks the unit with
+func supportedKeysMapsUsedBy(decl *ast.FuncDecl, declarations declarations) map[string][]string {
+	used := make(map[string][]string)
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		if ident, ok := n.(*ast.Ident); ok {
+			if keys, ok := declarations.supportedKeysMaps[ident.Name]; ok {
+				used[ident.Name] = keys
+			}
+		}
+		return true
+	})
+	return used
+}
+
+// inspectSinkParams computes which parameters of every function reach a sink until no new one is found
+func inspectSinkParams(funcs map[string]*ast.FuncDecl) sinkParams {
+	sinks := make(sinkParams)
+	sinks.add(specifierFuncName, pathSink, 0)
+
+	for changed := true; changed; {
+		changed = false
+		for name, decl := range funcs {
+			walker := taintWalker{sinks: sinks}
+			walker.walk(decl, func(lookupBinding) {}, func(sink sinkKind, t taint) {
+				if t.param >= 0 && sinks.add(name, sink, t.param) {
+					changed = true
+				}
+			})
+		}
+	}
+	return sinks
+}
+
+// taintWalker follows the values of the parameters of a function and of the keys it looks up to the sinks
+type taintWalker struct {
+	sinks        sinkParams
+	declarations declarations
+	context      convertContext
+}
+
+func (w taintWalker) walk(decl *ast.FuncDecl, onLookup func(lookupBinding), onSink func(sinkKind, taint)) {
+	groupParam := groupParamName(decl)
+	tainted := make(map[string]map[taint]bool)
+	index := 0
+	for _, param := range decl.Type.Params.List {
+		for _, name := range param.Names {
+			tainted[name.Name] = map[taint]bool{{param: index}: true}
+			index++
+		}
+	}
+
+	// exprTaints returns where the values used by an expression come from
+	exprTaints := func(expr ast.Expr) map[taint]bool {
+		taints := make(map[taint]bool)
+		ast.Inspect(expr, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.FuncLit:
+				return false
+			case *ast.CallExpr:
+				if binding, ok := w.lookupCall(n, groupParam); ok {
+					taints[taint{param: -1, key: binding}] = true
+					return false
+				}
+			case *ast.Ident:
+				maps.Copy(taints, tainted[n.Name])
+			}
+			return true
+		})
+		return taints
+	}
+
+	taintIdent := func(expr ast.Expr, taints map[taint]bool) {
+		ident, ok := expr.(*ast.Ident)
+		if !ok || ident.Name == "_" || len(taints) == 0 {
+			return
+		}
+		if tainted[ident.Name] == nil {
+			tainted[ident.Name] = make(map[taint]bool)
+		}
+		maps.Copy(tainted[ident.Name], taints)
+	}
+
+	ast.Inspect(decl.Body, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncLit:
+			return false
+		case *ast.AssignStmt:
+			w.assign(n, exprTaints, taintIdent)
+		case *ast.RangeStmt:
+			taints := exprTaints(n.X)
+			taintIdent(n.Key, taints)
+			taintIdent(n.Value, taints)
+		case *ast.ValueSpec:
+			for i, name := range n.Names {
+				if i < len(n.Values) {
+					taintIdent(name, exprTaints(n.Values[i]))
+				}
+			}
+		case *ast.IndexExpr:
+			if ident, ok := n.X.(*ast.Ident); ok && ident.Name == unitsInfoMapName {
+				for t := range exprTaints(n.Index) {
+					onSink(referenceSink, t)
+				}
+			}
+		case *ast.CallExpr:
+			if binding, ok := w.lookupCall(n, groupParam); ok {
+				onLookup(binding)
+			}
+
+			ident, ok := n.Fun.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			for sink, params := range w.sinks[ident.Name] {
+				for param := range params {
+					if param < len(n.Args) {
+						for t := range exprTaints(n.Args[param]) {
+							onSink(sink, t)
+						}
+					}
+				}
+			}
+		}
+		return true
+	})
+}
+
+// assign taints the variables assigned with the values of tainted expressions. Only the value returned by a lookup is
+// tainted by the key and not whether it was found.
+func (w taintWalker) assign(
+	assign *ast.AssignStmt,
+	exprTaints func(ast.Expr) map[taint]bool,
+	taintIdent func(ast.Expr, map[taint]bool),
+) {
+	if len(assign.Lhs) == len(assign.Rhs) {
+		for i, lhs := range assign.Lhs {
+			taintIdent(lhs, exprTaints(assign.Rhs[i]))
+		}
+		return
+	}
+
+	if len(assign.Rhs) != 1 {
+		return
+	}
+
+	taints := exprTaints(assign.Rhs[0])
+	if call, ok := assign.Rhs[0].(*ast.CallExpr); ok && isLookupCall(call) {
+		taintIdent(assign.Lhs[0], taints)
+		return
+	}
+
+	for _, lhs := range assign.Lhs {
+		taintIdent(lhs, taints)
+	}
+}
+
+// isLookupCall is true for the calls reading a key like 'unit.Lookup(Group, Key)' or 'unit.HasKey(Group, Key)'
+func isLookupCall(call *ast.CallExpr) bool {
+	selector, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok || len(call.Args) < 2 {
+		return false
+	}
+	return strings.HasPrefix(selector.Sel.Name, "Lookup") || selector.Sel.Name == "HasKey"
+}
+
+// lookupCall returns the group and the key read by a call
+func (w taintWalker) lookupCall(call *ast.CallExpr, groupParam string) (lookupBinding, bool) {
+	if !isLookupCall(call) || w.declarations.groupConstants == nil {
+		return lookupBinding{}, false
+	}
+
+	group, ok := resolveGroup(call.Args[0], w.declarations, groupParam, w.context)
+	if !ok {
+		return lookupBinding{}, false
+	}
+
+	key, ok := resolveKey(call.Args[1], w.declarations)
+	if !ok {
+		return lookupBinding{}, false
+	}
+	return lookupBinding{group: group, key: key}, true
+}
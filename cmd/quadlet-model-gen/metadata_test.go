@@ -0,0 +1,53 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQuadletParserFieldsMetadata(t *testing.T) {
+	t.Parallel()
+
+	lookupFuncs, err := parseUnitFileGo()
+	require.NoError(t, err)
+
+	fieldsByGroup, _, err := parseQuadletPackage(quadletTestFile, unitfileTestFile, lookupFuncs)
+	require.NoError(t, err)
+
+	fields := make(map[string]field)
+	for group, groupFields := range fieldsByGroup {
+		for _, field := range groupFields {
+			fields[group+"."+field.Key] = field
+		}
+	}
+
+	type metadata struct {
+		Kind          string
+		Multiplicity  string
+		UnitTypes     []string
+		SpecifierPath bool
+	}
+	allUnitTypes := []string{"build", "container", "image", "kube", "network", "pod", "volume"}
+	tests := map[string]metadata{
+		"Container.Image":             {kindReference, multiplicitySingle, []string{"container"}, false},
+		"Container.Network":           {kindReference, multiplicityMultiple, []string{"container"}, false},
+		"Container.Volume":            {kindReference, multiplicityMultiple, []string{"container"}, true},
+		"Container.Mount":             {kindArgs, multiplicityMultiple, []string{"container"}, true},
+		"Container.EnvironmentFile":   {kindArgs, multiplicityMultiple, []string{"container"}, true},
+		"Container.Exec":              {kindArgs, multiplicitySingle, []string{"container"}, false},
+		"Container.Label":             {kindKeyValue, multiplicityMultiple, []string{"container"}, false},
+		"Container.StartWithPod":      {kindBool, multiplicitySingle, []string{"container"}, false},
+		"Container.RemapUidSize":      {kindUint32, multiplicitySingle, []string{"container"}, false},
+		"Kube.Yaml":                   {kindString, multiplicitySingle, []string{"kube"}, true},
+		"Pod.Network":                 {kindReference, multiplicityMultiple, []string{"pod"}, false},
+		"Quadlet.DefaultDependencies": {kindBool, multiplicitySingle, allUnitTypes, false},
+		"Service.KillMode":            {kindString, multiplicitySingle, []string{"container", "kube"}, false},
+	}
+	for name, expected := range tests {
+		field, ok := fields[name]
+		require.True(t, ok, name)
+		assert.Equal(t, expected, metadata{field.Kind, field.Multiplicity, field.UnitTypes, field.SpecifierPath}, name)
+	}
+}
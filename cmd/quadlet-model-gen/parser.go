@@ -1,45 +1,46 @@
 package main
 
 import (
+	"cmp"
+	"errors"
+	"fmt"
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"maps"
 	"os"
+	"slices"
 	"strings"
 
 	"github.com/AhmedMoalla/quadlet-lint/pkg/utils"
 )
 
-var keyMapByGroup = map[string]string{
-	"Container": "supportedContainerKeys",
-	"Volume":    "supportedVolumeKeys",
-	"Network":   "supportedNetworkKeys",
-	"Kube":      "supportedKubeKeys",
-	"Image":     "supportedImageKeys",
-	"Build":     "supportedBuildKeys",
-	"Pod":       "supportedPodKeys",
-	"Quadlet":   "supportedQuadletKeys",
-}
-
-var groupByKeyMap = utils.ReverseMap(keyMapByGroup)
-
-// Approximate number of elements present in the source files
-const (
-	nbGroups          = 11
-	nbConstants       = 150
-	nbKeysPerGroup    = 50
-	nbLookupFunctions = 15
-)
-
 type sourceFileData struct {
-	fieldsByGroup map[string][]field
-	lookupFuncs   map[string]lookupFunc
+	podmanVersion string
+	// systemdVersion is only set for the model of the systemd directives
+	systemdVersion string
+	fieldsByGroup  map[string][]field
+	lookupFuncs    map[string]lookupFunc
+	constraints    constraintsByUnitType
 }
 
 type field struct {
 	Group      string
 	Key        string
 	LookupFunc lookupFunc
+	// Since is the first generated Podman version supporting the key
+	Since       string
+	Deprecated  bool
+	Replacement string
+	// Kind is the kind of the values of the key like bool or reference
+	Kind         string
+	Multiplicity string
+	// UnitTypes are the unit types whose conversion uses the key
+	UnitTypes []string
+	// SpecifierPath is true when the value is a path that can start with a systemd specifier
+	SpecifierPath bool
+	// Doc is the documentation of the key in the man page. It is nil when the man page is not provided.
+	Doc *keyDoc
 }
 
 type lookupFunc struct {
@@ -53,7 +54,7 @@ func parseUnitFileParserSourceFile(file *os.File) (map[string]lookupFunc, error)
 		return nil, err
 	}
 
-	lookupFuncs := make(map[string]lookupFunc, nbLookupFunctions)
+	lookupFuncs := make(map[string]lookupFunc)
 	for _, decl := range parsed.Decls {
 		decl, ok := decl.(*ast.FuncDecl)
 		if !ok || !strings.HasPrefix(decl.Name.Name, "Lookup") {
@@ -78,93 +79,116 @@ func parseUnitFileParserSourceFile(file *os.File) (map[string]lookupFunc, error)
 	return lookupFuncs, nil
 }
 
-func parseQuadletSourceFile(file *os.File, lookupFuncs map[string]lookupFunc) (map[string][]field, error) {
-	parsed, err := parser.ParseFile(token.NewFileSet(), file.Name(), nil, parser.SkipObjectResolution)
+// parseQuadletPackage parses the fields of every group from the quadlet package of quadletFile type-checked against
+// the parser package of unitfileFile. The fields of the groups having a map of supported keys are its keys. The fields
+// of the other groups are the keys looked up in them. It fails when the lookup function of a supported key is not
+// found.
+func parseQuadletPackage(
+	quadletFile, unitfileFile string,
+	lookupFuncs map[string]lookupFunc,
+) (map[string][]field, constraintsByUnitType, error) {
+	pkg, err := loadQuadletPackage(quadletFile, unitfileFile)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	declarations := inspectQuadletSourceFileDeclarations(parsed)
-	calls := inspectQuadletSourceFileLookupCalls(parsed, declarations, lookupFuncs)
-	lookupFuncByGroupKey, additionalFields := parseLookupCalls(declarations, calls)
-
-	groupConstants := declarations.groupConstants
-	fieldsByGroup := make(map[string][]field, len(groupConstants))
-	for _, group := range groupConstants {
-		keyMapName := keyMapByGroup[group]
-		for _, keyConstName := range declarations.supportedKeysMaps[keyMapName] {
-			key := declarations.keyConstants[keyConstName]
-			fieldsByGroup[group] = append(fieldsByGroup[group], field{
-				Group:      group,
-				Key:        key,
-				LookupFunc: lookupFuncByGroupKey[group][key],
-			})
+	inspection := inspectQuadletPackage(pkg, lookupFuncs)
+	declarations := inspection.declarations
+	if len(declarations.supportedKeysMaps) == 0 {
+		return nil, nil, errors.New("no map of supported keys was found along with a group")
+	}
+
+	fieldsByGroup := make(map[string][]field)
+	unclassified := make([]string, 0)
+	for _, name := range slices.Sorted(maps.Keys(declarations.supportedKeysMaps)) {
+		group := declarations.supportedKeysGroups[name]
+		for _, key := range declarations.supportedKeysMaps[name] {
+			lookupFunc, ok := selectLookupFunc(inspection.lookupFuncs[lookupBinding{group: group, key: key}])
+			if !ok {
+				unclassified = append(unclassified, fmt.Sprintf("%s.%s", group, key))
+				continue
+			}
+			fieldsByGroup[group] = append(fieldsByGroup[group], field{Group: group, Key: key, LookupFunc: lookupFunc})
 		}
 	}
 
-	// Easier this way...
-	fieldsByGroup = utils.MergeMaps(fieldsByGroup, additionalFields)
+	if len(unclassified) > 0 {
+		return nil, nil, unclassifiedKeysError(unclassified, inspection.unresolved, pkg.undeclared)
+	}
+
+	groupsWithSupportedKeys := utils.ReverseMap(declarations.supportedKeysGroups)
+	for _, binding := range slices.SortedFunc(maps.Keys(inspection.lookupFuncs), compareLookupBindings) {
+		if _, ok := groupsWithSupportedKeys[binding.group]; ok {
+			continue
+		}
+
+		lookupFunc, _ := selectLookupFunc(inspection.lookupFuncs[binding])
+		fieldsByGroup[binding.group] = append(fieldsByGroup[binding.group],
+			field{Group: binding.group, Key: binding.key, LookupFunc: lookupFunc})
+	}
+
 	for group, fields := range fieldsByGroup {
 		for i, field := range fields {
-			if strings.HasPrefix(field.Key, "Health") {
-				fieldsByGroup[group][i].LookupFunc = lookupFuncs["Lookup"]
+			if replacement, deprecated := declarations.deprecatedKeys[field.Key]; deprecated {
+				fieldsByGroup[group][i].Deprecated = true
+				fieldsByGroup[group][i].Replacement = replacement
 			}
 		}
 	}
 
-	return fieldsByGroup, nil
+	file := pkg.file()
+	setFieldsMetadata(fieldsByGroup, inspectQuadletSourceFileUsages(file, declarations))
+
+	return fieldsByGroup, inspectQuadletSourceFileConstraints(file, declarations, fieldsByGroup), nil
 }
 
-func parseLookupCalls(
-	declarations declarations,
-	calls lookupFuncCalls,
-) (map[string]map[string]lookupFunc, map[string][]field) {
-	otherConstants := declarations.otherConstants
-	groupConstants := declarations.groupConstants
-	keyConstants := declarations.keyConstants
-	supportedKeysMaps := declarations.supportedKeysMaps
-
-	lookupFuncByGroupKey := make(map[string]map[string]lookupFunc, nbGroups)
-	for _, group := range groupConstants {
-		lookupFuncByGroupKey[group] = make(map[string]lookupFunc, nbKeysPerGroup)
+// selectLookupFunc returns the lookup function of a key looked up by several functions. The functions returning
+// multiple values take precedence because they tell that the key can be repeated. Otherwise, the first function by
+// name is kept which selects Lookup over its typed variants like for Notify which is both read as a string and as a
+// boolean. The key then accepts every value Quadlet understands.
+func selectLookupFunc(lookupFuncs map[string]lookupFunc) (lookupFunc, bool) {
+	if len(lookupFuncs) == 0 {
+		return lookupFunc{}, false
 	}
 
-	for args, lookupFunc := range calls.direct {
-		lookupFuncByGroupKey[args.group][args.key.name] = lookupFunc
-	}
+	return slices.MaxFunc(slices.Collect(maps.Values(lookupFuncs)), func(a, b lookupFunc) int {
+		if a.Multiple != b.Multiple {
+			if a.Multiple {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(b.Name, a.Name)
+	}), true
+}
 
-	for args, lookupFunc := range calls.alternative {
-		lookupFuncByGroupKey[args.group][args.key.name] = lookupFunc
+func compareLookupBindings(a, b lookupBinding) int {
+	return cmp.Or(strings.Compare(a.group, b.group), strings.Compare(a.key, b.key))
+}
+
+// unclassifiedKeysError lists the supported keys whose lookup function was not found along with the lookups whose
+// group or key could not be resolved which are likely to look them up. The identifiers that the loaded files of the
+// quadlet package do not declare are listed last since the lookups may be in the files declaring them.
+func unclassifiedKeysError(keys, unresolved, undeclared []string) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "could not find the lookup function of %d supported keys:", len(keys))
+	for _, key := range keys {
+		fmt.Fprintf(&b, "\n  %s", key)
 	}
 
-	additionalFields := make(map[string][]field, len(groupConstants))
-	for args, lookupFunc := range calls.ambiguous {
-		if group, ok := groupConstants[args.group]; ok {
-			var key string
-			if args.key.literal {
-				key = args.key.name
-			} else {
-				key = otherConstants[args.key.name]
-			}
-			additionalFields[group] = append(additionalFields[group], field{
-				Group:      group,
-				Key:        key,
-				LookupFunc: lookupFunc,
-			})
-			continue
+	if len(unresolved) > 0 {
+		b.WriteString("\nthe group or the key of these lookups could not be resolved:")
+		for _, position := range unresolved {
+			fmt.Fprintf(&b, "\n  %s", position)
 		}
+	}
 
-		for mapName, keyVarNames := range supportedKeysMaps {
-			group := groupByKeyMap[mapName]
-			for _, keyVarName := range keyVarNames {
-				if keyVarName == args.key.name {
-					key := keyConstants[args.key.name]
-					lookupFuncByGroupKey[group][key] = lookupFunc
-					break
-				}
-			}
+	if len(undeclared) > 0 {
+		fmt.Fprintf(&b, "\nthese identifiers are not declared by the loaded files of the quadlet package. Provide "+
+			"all its files with -%s flag:", sourceDirFlag)
+		for _, identifier := range undeclared {
+			fmt.Fprintf(&b, "\n  %s", identifier)
 		}
 	}
-
-	return lookupFuncByGroupKey, additionalFields
+	return errors.New(b.String())
 }
@@ -1,10 +1,19 @@
 package main
 
 import (
+	"go/ast"
+	"go/parser"
+	"go/token"
 	"os"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	quadletTestFile  = "testdata/v5.3.1/quadlet.go"
+	unitfileTestFile = "testdata/v5.3.1/unitfile.go"
 )
 
 func TestQuadletParser(t *testing.T) {
@@ -15,12 +24,7 @@ func TestQuadletParser(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	file, err := os.Open("testdata/v5.3.1/quadlet.go")
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	fieldsByGroup, err := parseQuadletSourceFile(file, lookupFuncs)
+	fieldsByGroup, _, err := parseQuadletPackage(quadletTestFile, unitfileTestFile, lookupFuncs)
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -70,7 +74,7 @@ func TestUnitFileParser(t *testing.T) {
 }
 
 func parseUnitFileGo() (map[string]lookupFunc, error) {
-	parserFile, err := os.Open("testdata/v5.3.1/unitfile.go")
+	parserFile, err := os.Open(unitfileTestFile)
 	if err != nil {
 		return nil, err
 	}
@@ -81,3 +85,73 @@ func parseUnitFileGo() (map[string]lookupFunc, error) {
 	}
 	return lookupFuncs, nil
 }
+
+func TestQuadletParserDeprecatedKeys(t *testing.T) {
+	t.Parallel()
+
+	lookupFuncs, err := parseUnitFileGo()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fieldsByGroup, _, err := parseQuadletPackage(quadletTestFile, unitfileTestFile, lookupFuncs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	deprecatedKeys := make(map[string][]string)
+	for group, fields := range fieldsByGroup {
+		for _, field := range fields {
+			if field.Deprecated {
+				deprecatedKeys[group] = append(deprecatedKeys[group], field.Key)
+			}
+		}
+	}
+
+	remapKeys := []string{"RemapGid", "RemapUid", "RemapUidSize", "RemapUsers"}
+	assert.ElementsMatch(t, append(remapKeys, "VolatileTmp"), deprecatedKeys["Container"])
+	assert.ElementsMatch(t, remapKeys, deprecatedKeys["Pod"])
+	assert.ElementsMatch(t, remapKeys, deprecatedKeys["Kube"])
+	assert.NotContains(t, deprecatedKeys, "Volume")
+}
+
+func TestDeprecationComment(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source      string
+		deprecated  bool
+		replacement string
+	}{
+		{`KeyRemapUid = "RemapUid" //nolint:stylecheck // deprecated`, true, ""},
+		{`KeyRemapUsers = "RemapUsers" // deprecated`, true, ""},
+		{`KeyRemapUsers = "RemapUsers" // Deprecated: use UserNS instead`, true, "use UserNS instead"},
+		{`KeyUserNS = "UserNS"`, false, ""},
+		{`KeyUserNS = "UserNS" // replaces the deprecated keys`, false, ""},
+	}
+	for _, test := range tests {
+		parsed, err := parser.ParseFile(token.NewFileSet(), "", "package quadlet\nconst "+test.source,
+			parser.ParseComments)
+		require.NoError(t, err)
+
+		spec, _ := parsed.Decls[0].(*ast.GenDecl).Specs[0].(*ast.ValueSpec)
+		replacement, deprecated := deprecationComment(spec)
+		assert.Equal(t, test.deprecated, deprecated, test.source)
+		assert.Equal(t, test.replacement, replacement, test.source)
+	}
+}
+
+// checkTestPackage type-checks a quadlet package made of source against the parser package of the test data
+func checkTestPackage(t *testing.T, source string) quadletPackage {
+	t.Helper()
+
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, "quadlet.go", source, parser.SkipObjectResolution|parser.ParseComments)
+	require.NoError(t, err)
+	parserFiles, err := parsePackageFiles(fset, unitfileTestFile, quadletTestFile)
+	require.NoError(t, err)
+
+	pkg, err := checkQuadletPackage(fset, []*ast.File{file}, parserFiles)
+	require.NoError(t, err)
+	return pkg
+}
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"go/ast"
 	"go/parser"
+	"go/printer"
 	"go/token"
 	"go/types"
 	"os"
@@ -44,7 +45,7 @@ func TestQuadletModelGen(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	parseAndGenerateFiles(quadlet, unitfile)
+	parseAndGenerateFiles(quadlet, unitfile, nil, podmanVersion, false)
 	defer os.RemoveAll(generatedDirName)
 	generatedDir, err := os.Open(generatedRefDirName)
 	if err != nil && os.IsNotExist(err) {
@@ -211,7 +212,7 @@ func extractStructDecl(spec ast.Spec) (structDecl, bool) {
 	return structDecl{Name: typeSpec.Name.Name, Fields: fields}, true
 }
 
-// computeMapField can handle nested maps with string keys
+// computeMapField can handle nested maps with string keys. Other composite literals are compared as strings.
 func computeMapField(spec *ast.CompositeLit) any {
 	result := make(map[string]any, len(spec.Elts))
 	for _, elt := range spec.Elts {
@@ -226,7 +227,11 @@ func computeMapField(spec *ast.CompositeLit) any {
 		}
 
 		if value, ok := kv.Value.(*ast.CompositeLit); ok {
-			result[key.Value] = computeMapField(value)
+			if nested := computeMapField(value); nested != nil {
+				result[key.Value] = nested
+			} else {
+				result[key.Value] = exprSource(value)
+			}
 		} else if value, ok := kv.Value.(*ast.SelectorExpr); ok {
 			result[key.Value] = types.ExprString(value)
 		} else {
@@ -237,6 +242,15 @@ func computeMapField(spec *ast.CompositeLit) any {
 	return result
 }
 
+// exprSource prints the whole expression unlike types.ExprString which elides composite literals
+func exprSource(expr ast.Expr) string {
+	var b strings.Builder
+	if err := printer.Fprint(&b, token.NewFileSet(), expr); err != nil {
+		return types.ExprString(expr)
+	}
+	return b.String()
+}
+
 func listAllFiles(dir string) ([]string, error) {
 	dirEntries, err := os.ReadDir(dir)
 	if err != nil {
@@ -0,0 +1,189 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	podmanGithubTagsURL = "https://raw.githubusercontent.com/containers/podman/refs/tags"
+
+	// Paths of the source files relative to the root of podman's repository
+	quadletFilePath        = "pkg/systemd/quadlet/quadlet.go"
+	unitfileParserFilePath = "pkg/systemd/parser/unitfile.go"
+
+	cacheDirPerm = 0755
+)
+
+// sourceOptions tells where the source files used for code generation are taken from
+type sourceOptions struct {
+	podmanVersion string
+	// sourceDir is the root of a checkout of podman's repository
+	sourceDir string
+	// quadletFile and unitfileFile are explicit paths to the source files. They take precedence over sourceDir.
+	quadletFile  string
+	unitfileFile string
+	// cacheDir is where downloaded source files are kept by podman tag. Nothing is cached when it is empty.
+	cacheDir string
+	baseURL  string
+}
+
+// sourceFiles are the paths of the source files used for code generation
+type sourceFiles struct {
+	quadlet  string
+	unitfile string
+	// temporary is true when the files were downloaded to a temporary directory that must be removed after generation
+	temporary bool
+	dir       string
+}
+
+func (f sourceFiles) remove() {
+	if f.temporary {
+		os.RemoveAll(f.dir)
+	}
+}
+
+func (o sourceOptions) local() bool {
+	return o.sourceDir != "" || o.quadletFile != "" || o.unitfileFile != ""
+}
+
+// resolveSourceFiles finds the source files without touching the network when they are available locally. They are
+// taken in order from the explicit files, the source directory and the cache. Otherwise, they are downloaded from
+// GitHub and kept in the cache if there is one.
+func resolveSourceFiles(options sourceOptions) (sourceFiles, error) {
+	if options.local() {
+		return localSourceFiles(options)
+	}
+
+	if options.podmanVersion == "" {
+		return sourceFiles{}, errNoPodmanVersion
+	}
+
+	if options.cacheDir == "" {
+		// The files get their own directory since the other files of their directory are loaded with them
+		dir, err := os.MkdirTemp("", "podman-"+options.podmanVersion+"-*")
+		if err != nil {
+			return sourceFiles{}, fmt.Errorf("could not create temporary directory: %w", err)
+		}
+		return downloadSourceFiles(options, dir, true)
+	}
+
+	dir := filepath.Join(options.cacheDir, options.podmanVersion)
+	if err := os.MkdirAll(dir, cacheDirPerm); err != nil {
+		return sourceFiles{}, fmt.Errorf("could not create cache directory: %w", err)
+	}
+	return downloadSourceFiles(options, dir, false)
+}
+
+func localSourceFiles(options sourceOptions) (sourceFiles, error) {
+	files := sourceFiles{quadlet: options.quadletFile, unitfile: options.unitfileFile}
+	if files.quadlet == "" && options.sourceDir != "" {
+		files.quadlet = filepath.Join(options.sourceDir, filepath.FromSlash(quadletFilePath))
+	}
+	if files.unitfile == "" && options.sourceDir != "" {
+		files.unitfile = filepath.Join(options.sourceDir, filepath.FromSlash(unitfileParserFilePath))
+	}
+
+	if files.quadlet == "" || files.unitfile == "" {
+		return sourceFiles{}, fmt.Errorf("both -%s and -%s flags must be provided when -%s flag is not",
+			quadletFileFlag, unitfileFileFlag, sourceDirFlag)
+	}
+
+	for _, file := range []string{files.quadlet, files.unitfile} {
+		if _, err := os.Stat(file); err != nil {
+			return sourceFiles{}, fmt.Errorf("could not find source file: %w", err)
+		}
+	}
+
+	return files, nil
+}
+
+// resolveDocsFile returns the man page documenting the keys. It is only read locally from the explicit file or from
+// the source directory and is empty when neither has it.
+func resolveDocsFile(docsFile, sourceDir string) string {
+	if docsFile != "" || sourceDir == "" {
+		return docsFile
+	}
+
+	path := filepath.Join(sourceDir, filepath.FromSlash(docsFilePath))
+	if _, err := os.Stat(path); err != nil {
+		return ""
+	}
+	return path
+}
+
+// downloadSourceFiles downloads the source files to dir. When they are not temporary, the files already in dir are
+// reused instead of being downloaded again.
+func downloadSourceFiles(options sourceOptions, dir string, temporary bool) (sourceFiles, error) {
+	files := sourceFiles{temporary: temporary, dir: dir}
+	destinations := map[string]*string{
+		quadletFilePath:        &files.quadlet,
+		unitfileParserFilePath: &files.unitfile,
+	}
+	for filePath, dest := range destinations {
+		name := filepath.Base(filePath)
+		if !temporary {
+			*dest = filepath.Join(dir, name)
+			if _, err := os.Stat(*dest); err == nil {
+				continue
+			}
+		}
+
+		file, err := downloadSourceFile(options.baseURL, options.podmanVersion, filePath, dir)
+		if err != nil {
+			files.remove()
+			return sourceFiles{}, fmt.Errorf("could not download %s source file: %w", name, err)
+		}
+
+		if temporary {
+			*dest = file
+		} else if err = os.Rename(file, *dest); err != nil {
+			os.Remove(file)
+			return sourceFiles{}, fmt.Errorf("could not cache %s source file: %w", name, err)
+		}
+	}
+
+	return files, nil
+}
+
+// downloadSourceFile downloads a file of podman's repository at the given tag to a new file in dir and returns its
+// path
+func downloadSourceFile(baseURL, version, filePath, dir string) (string, error) {
+	url := fmt.Sprintf("%s/%s/%s", baseURL, version, filePath)
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+
+	response, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to download from '%s': %w", url, err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unexpected status '%s' when downloading from '%s'", response.Status, url)
+	}
+
+	fileName := filepath.Base(filePath)
+	ext := filepath.Ext(fileName)
+	fileName = strings.TrimSuffix(fileName, ext)
+	file, err := os.CreateTemp(dir, fileName+"-*"+ext)
+	if err != nil {
+		return "", fmt.Errorf("failed to create temporary file to copy the content of %s: %w", filePath, err)
+	}
+	defer file.Close()
+
+	_, err = io.Copy(file, response.Body)
+	if err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("failed to copy file contents: %w", err)
+	}
+
+	return file.Name(), nil
+}
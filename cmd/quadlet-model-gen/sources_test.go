@@ -0,0 +1,119 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSourceFilesFromSourceDir(t *testing.T) {
+	t.Parallel()
+
+	sourceDir := t.TempDir()
+	writeFile(t, filepath.Join(sourceDir, quadletFilePath), "package quadlet")
+	writeFile(t, filepath.Join(sourceDir, unitfileParserFilePath), "package parser")
+
+	files, err := resolveSourceFiles(sourceOptions{sourceDir: sourceDir, baseURL: "http://invalid.invalid"})
+	require.NoError(t, err)
+	assert.Equal(t, sourceFiles{
+		quadlet:  filepath.Join(sourceDir, quadletFilePath),
+		unitfile: filepath.Join(sourceDir, unitfileParserFilePath),
+	}, files)
+
+	// Explicit files take precedence over the source directory
+	files, err = resolveSourceFiles(sourceOptions{sourceDir: sourceDir, quadletFile: "testdata/v5.3.1/quadlet.go"})
+	require.NoError(t, err)
+	assert.Equal(t, "testdata/v5.3.1/quadlet.go", files.quadlet)
+	assert.Equal(t, filepath.Join(sourceDir, unitfileParserFilePath), files.unitfile)
+}
+
+func TestResolveSourceFilesFromFiles(t *testing.T) {
+	t.Parallel()
+
+	files, err := resolveSourceFiles(sourceOptions{
+		quadletFile:  "testdata/v5.3.1/quadlet.go",
+		unitfileFile: "testdata/v5.3.1/unitfile.go",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, sourceFiles{quadlet: "testdata/v5.3.1/quadlet.go", unitfile: "testdata/v5.3.1/unitfile.go"}, files)
+
+	_, err = resolveSourceFiles(sourceOptions{quadletFile: "testdata/v5.3.1/quadlet.go"})
+	require.ErrorContains(t, err, "-unitfile-file")
+
+	_, err = resolveSourceFiles(sourceOptions{quadletFile: "missing.go", unitfileFile: "testdata/v5.3.1/unitfile.go"})
+	require.ErrorIs(t, err, os.ErrNotExist)
+}
+
+func TestResolveSourceFilesFromCache(t *testing.T) {
+	t.Parallel()
+
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte("// " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	options := sourceOptions{podmanVersion: "v5.3.1", cacheDir: t.TempDir(), baseURL: server.URL}
+	files, err := resolveSourceFiles(options)
+	require.NoError(t, err)
+	assert.Equal(t, int32(2), requests.Load())
+	assert.Equal(t, filepath.Join(options.cacheDir, "v5.3.1", "quadlet.go"), files.quadlet)
+	assert.Equal(t, filepath.Join(options.cacheDir, "v5.3.1", "unitfile.go"), files.unitfile)
+	assert.False(t, files.temporary)
+
+	content, err := os.ReadFile(files.quadlet)
+	require.NoError(t, err)
+	assert.Equal(t, "// /v5.3.1/"+quadletFilePath, string(content))
+
+	// The cached files are used without downloading them again
+	cached, err := resolveSourceFiles(options)
+	require.NoError(t, err)
+	assert.Equal(t, files, cached)
+	assert.Equal(t, int32(2), requests.Load())
+
+	entries, err := os.ReadDir(filepath.Join(options.cacheDir, "v5.3.1"))
+	require.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestResolveSourceFilesDownload(t *testing.T) {
+	t.Parallel()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v0.0.0/"+quadletFilePath {
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer server.Close()
+
+	files, err := resolveSourceFiles(sourceOptions{podmanVersion: "v5.3.1", baseURL: server.URL})
+	require.NoError(t, err)
+	assert.True(t, files.temporary)
+	assert.FileExists(t, files.quadlet)
+	assert.FileExists(t, files.unitfile)
+	assert.Equal(t, files.dir, filepath.Dir(files.quadlet))
+	files.remove()
+	assert.NoDirExists(t, files.dir)
+
+	cacheDir := t.TempDir()
+	_, err = resolveSourceFiles(sourceOptions{podmanVersion: "v0.0.0", cacheDir: cacheDir, baseURL: server.URL})
+	require.ErrorContains(t, err, "404")
+	assert.NoFileExists(t, filepath.Join(cacheDir, "v0.0.0", "quadlet.go"))
+
+	_, err = resolveSourceFiles(sourceOptions{baseURL: server.URL})
+	require.ErrorContains(t, err, podmanVersionEnvKey)
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+
+	require.NoError(t, os.MkdirAll(filepath.Dir(name), 0755))
+	require.NoError(t, os.WriteFile(name, []byte(content), 0600))
+}
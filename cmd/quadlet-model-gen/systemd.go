@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+const (
+	systemdCommand = "systemd"
+
+	systemdVersionFlag = "systemd-version"
+	gperfFileFlag      = "gperf-file"
+	directivesFileFlag = "directives-file"
+
+	systemdGeneratedDirName       = "systemd"
+	systemdGeneratedMarkerComment = "// Code generated by \"quadlet-model-gen\"; SystemdVersion=%s; DO NOT EDIT.\n"
+)
+
+// systemdGroups are the groups of the units that Quadlet passes to systemd along with the service it generates
+var systemdGroups = []string{"Unit", "Service", "Install"}
+
+// systemdLookupFunc looks up the directives. All their values are looked up since the sources do not tell which
+// directives accept a single value.
+var systemdLookupFunc = lookupFunc{Name: "LookupAll", Multiple: true}
+
+var (
+	systemdVersionRegexp = regexp.MustCompile(`^v\d+(\.\d+)?(-rc\d+)?$`)
+	// gperfDirectiveRegexp matches the directives like 'Unit.Description, config_parse_unit_string_printf, ...'. The
+	// group is a template variable like '{{type}}' in the macros shared by several groups.
+	gperfDirectiveRegexp = regexp.MustCompile(`^(\w+|\{\{\s*\w+\s*}})\.(\w+)\s*,`)
+	gperfMacroRegexp     = regexp.MustCompile(`^\{%-?\s*macro\s+(\w+)\(\s*(\w+)\s*\)\s*-?%}$`)
+	gperfEndMacroRegexp  = regexp.MustCompile(`^\{%-?\s*endmacro\s*-?%}$`)
+	// gperfMacroCallRegexp matches the calls of the macros like "{{ EXEC_CONTEXT_CONFIG_ITEMS('Service') }}"
+	gperfMacroCallRegexp  = regexp.MustCompile(`^\{\{\s*(\w+)\(\s*'(\w+)'\s*\)\s*}}$`)
+	directivesGroupRegexp = regexp.MustCompile(`^\[(\w+)]$`)
+	directivesKeyRegexp   = regexp.MustCompile(`^(\w+)(=.*)?$`)
+)
+
+// runSystemd generates the model of the systemd directives from one of systemd's source files given as arguments
+func runSystemd(args []string, w io.Writer) error {
+	flags := flag.NewFlagSet(systemdCommand, flag.ContinueOnError)
+	flags.SetOutput(w)
+	flags.Usage = func() {
+		fmt.Fprintf(w, "Usage: quadlet-model-gen %s [flags]\n", systemdCommand)
+		flags.PrintDefaults()
+	}
+	version := flags.String(systemdVersionFlag, "", "systemd's tag the directives are taken from like v256")
+	gperfFile := flags.String(gperfFileFlag, "", "load-fragment-gperf.gperf.in source file of systemd")
+	directivesFile := flags.String(directivesFileFlag, "",
+		"List of the directives by group like the output of 'systemd --dump-configuration-items'")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	if !systemdVersionRegexp.MatchString(*version) {
+		return fmt.Errorf("systemd version '%s' is not a tag like v256. Use -%s flag", *version, systemdVersionFlag)
+	}
+	if (*gperfFile == "") == (*directivesFile == "") {
+		return fmt.Errorf("exactly one of -%s and -%s flags must be provided", gperfFileFlag, directivesFileFlag)
+	}
+
+	parse, path := parseDirectivesList, *directivesFile
+	if *gperfFile != "" {
+		parse, path = parseGperfDirectives, *gperfFile
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("could not open systemd source file: %w", err)
+	}
+	defer file.Close()
+
+	directivesByGroup, err := parse(file)
+	if err != nil {
+		return fmt.Errorf("could not parse systemd source file: %w", err)
+	}
+
+	workingDir, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	outputDir := filepath.Join(workingDir, generatedDirName, systemdGeneratedDirName)
+	if err = generateSystemdSourceFiles(outputDir, *version, directivesByGroup); err != nil {
+		return fmt.Errorf("could not generate source files: %w", err)
+	}
+	return nil
+}
+
+// parseGperfDirectives reads the directives by group from the load-fragment-gperf.gperf.in template of systemd. The
+// directives of the macros are added to the group they are called with. Every branch of the conditions is read since
+// the directives compiled out are still accepted with a warning.
+func parseGperfDirectives(reader io.Reader) (map[string][]string, error) {
+	directives := newDirectiveSet()
+	macros := make(map[string][]string)
+	var macro, macroParam string
+
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if matches := gperfMacroRegexp.FindStringSubmatch(text); matches != nil {
+			macro, macroParam = matches[1], matches[2]
+			macros[macro] = make([]string, 0)
+			continue
+		}
+
+		if gperfEndMacroRegexp.MatchString(text) {
+			macro = ""
+			continue
+		}
+
+		if matches := gperfMacroCallRegexp.FindStringSubmatch(text); matches != nil {
+			called, ok := macros[matches[1]]
+			if !ok {
+				return nil, fmt.Errorf("macro %s is called at line %d before being defined", matches[1], line)
+			}
+			for _, name := range called {
+				directives.add(matches[2], name)
+			}
+			continue
+		}
+
+		matches := gperfDirectiveRegexp.FindStringSubmatch(text)
+		if matches == nil {
+			continue
+		}
+
+		group, name := matches[1], matches[2]
+		if !strings.HasPrefix(group, "{{") {
+			directives.add(group, name)
+			continue
+		}
+
+		variable := strings.TrimSpace(strings.Trim(group, "{}"))
+		if macro == "" || variable != macroParam {
+			return nil, fmt.Errorf("group %s of directive %s at line %d is not a parameter of a macro", group, name,
+				line)
+		}
+		if !slices.Contains(macros[macro], name) {
+			macros[macro] = append(macros[macro], name)
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return directives.byGroup()
+}
+
+// parseDirectivesList reads the directives listed under the headers of their groups like '[Service]'. The values
+// assigned to the directives like their parsers are ignored. Empty lines and comments are skipped.
+func parseDirectivesList(reader io.Reader) (map[string][]string, error) {
+	directives := newDirectiveSet()
+	group := ""
+
+	scanner := bufio.NewScanner(reader)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, ";") {
+			continue
+		}
+
+		if matches := directivesGroupRegexp.FindStringSubmatch(text); matches != nil {
+			group = matches[1]
+			continue
+		}
+
+		matches := directivesKeyRegexp.FindStringSubmatch(text)
+		if matches == nil {
+			return nil, fmt.Errorf("line %d is neither a group nor a directive: '%s'", line, text)
+		}
+		if group == "" {
+			return nil, fmt.Errorf("directive %s at line %d is not in a group", matches[1], line)
+		}
+		directives.add(group, matches[1])
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return directives.byGroup()
+}
+
+// directiveSet collects the directives of the systemd groups in the order they are found without duplicates
+type directiveSet struct {
+	directives map[string][]string
+	seen       map[string]bool
+}
+
+func newDirectiveSet() directiveSet {
+	return directiveSet{directives: make(map[string][]string), seen: make(map[string]bool)}
+}
+
+func (s directiveSet) add(group, name string) {
+	if !slices.Contains(systemdGroups, group) || s.seen[group+"."+name] {
+		return
+	}
+
+	s.seen[group+"."+name] = true
+	s.directives[group] = append(s.directives[group], name)
+}
+
+// byGroup returns the directives by group. Every systemd group must have directives.
+func (s directiveSet) byGroup() (map[string][]string, error) {
+	for _, group := range systemdGroups {
+		if len(s.directives[group]) == 0 {
+			return nil, fmt.Errorf("no directive was found in group [%s]", group)
+		}
+	}
+	return s.directives, nil
+}
+
+// generateSystemdSourceFiles generates the model of the systemd directives in outputDir. It has the same shape as the
+// model of Quadlet's keys with a package by group along with the Groups and Fields of every group.
+func generateSystemdSourceFiles(outputDir, version string, directivesByGroup map[string][]string) error {
+	err := os.MkdirAll(outputDir, generatedFilesPerm)
+	if err != nil {
+		return err
+	}
+
+	data := sourceFileData{systemdVersion: version, fieldsByGroup: make(map[string][]field, len(directivesByGroup))}
+	for group, names := range directivesByGroup {
+		for _, name := range slices.Sorted(slices.Values(names)) {
+			data.fieldsByGroup[group] = append(data.fieldsByGroup[group],
+				field{Group: group, Key: name, LookupFunc: systemdLookupFunc})
+		}
+	}
+
+	err = generateFile(outputDir, data, "groups.go", systemdGroupsFile)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range slices.Sorted(maps.Keys(data.fieldsByGroup)) {
+		groupLower := strings.ToLower(group)
+		err = generateFile(outputDir, data, fmt.Sprintf("%s/%s.go", groupLower, groupLower), groupFile(group))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// systemdGroupsFile declares the Groups and the Fields of the systemd directives along with the version of systemd
+func systemdGroupsFile(b *bytes.Buffer, data sourceFileData) {
+	groupsFile(systemdGeneratedDirName, baseModelPackageName+"/"+systemdGeneratedDirName)(b, data)
+	b.WriteString("\n// Version is the version of systemd the directives are taken from\n")
+	b.WriteString(fmt.Sprintf("const Version = %q\n", data.systemdVersion))
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGperfDirectives(t *testing.T) {
+	t.Parallel()
+
+	file, err := os.Open("testdata/systemd/load-fragment-gperf.gperf.in")
+	require.NoError(t, err)
+	defer file.Close()
+
+	directives, err := parseGperfDirectives(file)
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"Unit": {"Description", "Documentation", "After", "ConditionPathExists"},
+		"Service": {"Type", "Restart", "WorkingDirectory", "RootDirectory", "User", "SystemCallFilter", "SendSIGKILL",
+			"KillMode"},
+		"Install": {"Alias", "WantedBy"},
+	}, directives)
+}
+
+func TestParseGperfDirectivesErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"{{ EXEC_CONTEXT_CONFIG_ITEMS('Service') }}\n": "macro EXEC_CONTEXT_CONFIG_ITEMS is called at line 1 before " +
+			"being defined",
+		"Unit.Description, config_parse_unit_string_printf, 0, 0\n{{type}}.User, config_parse_user, 0, 0\n": "group " +
+			"{{type}} of directive User at line 2 is not a parameter of a macro",
+		"Unit.Description, config_parse_unit_string_printf, 0, 0\nService.Type, config_parse_service_type, 0, 0\n": "no " +
+			"directive was found in group [Install]",
+	}
+
+	for source, expected := range tests {
+		_, err := parseGperfDirectives(strings.NewReader(source))
+		require.EqualError(t, err, expected)
+	}
+}
+
+func TestParseDirectivesList(t *testing.T) {
+	t.Parallel()
+
+	directives, err := parseDirectivesList(strings.NewReader(`# Directives of systemd
+[Unit]
+Description=config_parse_unit_string_printf
+After=
+
+[Socket]
+ListenStream=config_parse_socket_listen
+
+[Service]
+; systemd.service(5)
+Type
+Type=config_parse_service_type
+
+[Install]
+WantedBy=
+`))
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"Unit":    {"Description", "After"},
+		"Service": {"Type"},
+		"Install": {"WantedBy"},
+	}, directives)
+
+	_, err = parseDirectivesList(strings.NewReader("Description=\n[Unit]"))
+	require.EqualError(t, err, "directive Description at line 1 is not in a group")
+
+	_, err = parseDirectivesList(strings.NewReader("[Unit]\nSome directive"))
+	require.EqualError(t, err, "line 2 is neither a group nor a directive: 'Some directive'")
+}
+
+func TestGenerateSystemdSourceFiles(t *testing.T) {
+	t.Parallel()
+
+	outputDir := t.TempDir()
+	require.NoError(t, generateSystemdSourceFiles(outputDir, "v256", map[string][]string{
+		"Unit":    {"Description", "After"},
+		"Service": {"Type"},
+		"Install": {"WantedBy"},
+	}))
+
+	groups, err := os.ReadFile(filepath.Join(outputDir, "groups.go"))
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(groups),
+		"// Code generated by \"quadlet-model-gen\"; SystemdVersion=v256; DO NOT EDIT.\npackage systemd\n"))
+	assert.Contains(t, string(groups), "\"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/systemd/unit\"")
+	assert.Contains(t, string(groups), "Unit    unit.GUnit")
+	assert.Contains(t, string(groups), "\"After\":       unit.After,")
+	assert.Contains(t, string(groups), "const Version = \"v256\"")
+
+	unit, err := os.ReadFile(filepath.Join(outputDir, "unit", "unit.go"))
+	require.NoError(t, err)
+	assert.Contains(t, string(unit), "package unit\n")
+	assert.Contains(t, string(unit), "After       = M.Field{Group: \"Unit\", Key: \"After\", "+
+		"LookupFunc: lookup.LookupAll}")
+}
+
+func TestRunSystemdErrors(t *testing.T) {
+	t.Parallel()
+
+	var out bytes.Buffer
+	require.EqualError(t, runSystemd([]string{"--directives-file", "systemd.directives"}, &out),
+		"systemd version '' is not a tag like v256. Use -systemd-version flag")
+	require.EqualError(t, runSystemd([]string{"--systemd-version", "256"}, &out),
+		"systemd version '256' is not a tag like v256. Use -systemd-version flag")
+	require.EqualError(t, runSystemd([]string{"--systemd-version", "v256"}, &out),
+		"exactly one of -gperf-file and -directives-file flags must be provided")
+	require.EqualError(t, runSystemd([]string{"--systemd-version", "v256", "--gperf-file", "a.gperf.in",
+		"--directives-file", "systemd.directives"}, &out),
+		"exactly one of -gperf-file and -directives-file flags must be provided")
+	require.ErrorContains(t, runSystemd([]string{"--systemd-version", "v256", "--gperf-file", "missing.gperf.in"},
+		&out), "could not open systemd source file")
+}
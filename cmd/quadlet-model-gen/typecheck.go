@@ -0,0 +1,207 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+)
+
+// unitFileTypeName is the type of the units in podman's parser package
+const unitFileTypeName = "UnitFile"
+
+// quadletPackage is podman's quadlet package type-checked along with the parser package it reads the units with
+type quadletPackage struct {
+	fset  *token.FileSet
+	files []*ast.File
+	info  *types.Info
+	scope *types.Scope
+	// unitFile is the type whose methods look up the keys of the units
+	unitFile *types.Named
+	// undeclared are the identifiers used by the quadlet package that none of its loaded files declares along with
+	// their first position. They are likely declared in files of the package that were not loaded.
+	undeclared []string
+}
+
+// file merges the declarations of every file of the package into a single file
+func (p quadletPackage) file() *ast.File {
+	merged := &ast.File{Name: p.files[0].Name}
+	for _, file := range p.files {
+		merged.Decls = append(merged.Decls, file.Decls...)
+	}
+	return merged
+}
+
+// loadQuadletPackage parses and type-checks the files of the packages of quadletFile and unitfileFile. Only the files
+// declaring the same package as the given files are loaded and neither package loads the file of the other one so
+// that both packages can share a directory like the cache directory.
+func loadQuadletPackage(quadletFile, unitfileFile string) (quadletPackage, error) {
+	fset := token.NewFileSet()
+	parserFiles, err := parsePackageFiles(fset, unitfileFile, quadletFile)
+	if err != nil {
+		return quadletPackage{}, fmt.Errorf("could not parse the parser package: %w", err)
+	}
+
+	quadletFiles, err := parsePackageFiles(fset, quadletFile, unitfileFile)
+	if err != nil {
+		return quadletPackage{}, fmt.Errorf("could not parse the quadlet package: %w", err)
+	}
+
+	return checkQuadletPackage(fset, quadletFiles, parserFiles)
+}
+
+// parsePackageFiles parses a file along with the other files of its directory declaring the same package. Test files
+// and the excluded file are ignored.
+func parsePackageFiles(fset *token.FileSet, file, excluded string) ([]*ast.File, error) {
+	parsed, err := parser.ParseFile(fset, file, nil, parser.SkipObjectResolution|parser.ParseComments)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(filepath.Dir(file))
+	if err != nil {
+		return nil, err
+	}
+
+	files := []*ast.File{parsed}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || filepath.Ext(name) != ".go" || strings.HasSuffix(name, "_test.go") ||
+			name == filepath.Base(file) {
+			continue
+		}
+
+		other := filepath.Join(filepath.Dir(file), name)
+		if sameFile(other, excluded) {
+			continue
+		}
+
+		clause, err := parser.ParseFile(token.NewFileSet(), other, nil, parser.PackageClauseOnly)
+		if err != nil || clause.Name.Name != parsed.Name.Name {
+			continue
+		}
+
+		otherParsed, err := parser.ParseFile(fset, other, nil, parser.SkipObjectResolution|parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, otherParsed)
+	}
+	return files, nil
+}
+
+func sameFile(a, b string) bool {
+	aInfo, err := os.Stat(a)
+	if err != nil {
+		return false
+	}
+	bInfo, err := os.Stat(b)
+	return err == nil && os.SameFile(aInfo, bInfo)
+}
+
+// checkQuadletPackage type-checks the quadlet package against the parser package. The packages they import from the
+// standard library and from other modules are replaced by empty packages because only their own declarations are
+// inspected. The type errors caused by these missing declarations are ignored but the identifiers of the quadlet
+// package that are not declared at all are kept.
+func checkQuadletPackage(fset *token.FileSet, quadletFiles, parserFiles []*ast.File) (quadletPackage, error) {
+	importer := &stubImporter{packages: make(map[string]*types.Package)}
+	config := types.Config{Importer: importer, Error: func(error) {}}
+
+	// The files are checked under the names of the upstream packages since copies like the test data may declare
+	// another package
+	renamePackage(parserFiles, path.Base(path.Dir(unitfileParserFilePath)))
+	renamePackage(quadletFiles, path.Base(path.Dir(quadletFilePath)))
+
+	parserPackage, _ := config.Check(path.Dir(unitfileParserFilePath), fset, parserFiles, nil)
+	typeName, ok := parserPackage.Scope().Lookup(unitFileTypeName).(*types.TypeName)
+	if !ok {
+		return quadletPackage{}, fmt.Errorf("could not find type %s in the parser package", unitFileTypeName)
+	}
+	unitFile, ok := typeName.Type().(*types.Named)
+	if !ok {
+		return quadletPackage{}, fmt.Errorf("type %s of the parser package is not a named type", unitFileTypeName)
+	}
+	importer.parser = parserPackage
+
+	info := &types.Info{
+		Types:      make(map[ast.Expr]types.TypeAndValue),
+		Defs:       make(map[*ast.Ident]types.Object),
+		Uses:       make(map[*ast.Ident]types.Object),
+		Selections: make(map[*ast.SelectorExpr]*types.Selection),
+	}
+	undeclared := newUndeclaredIdentifiers()
+	config.Error = undeclared.add
+	quadlet, _ := config.Check(path.Dir(quadletFilePath), fset, quadletFiles, info)
+	return quadletPackage{fset: fset, files: quadletFiles, info: info, scope: quadlet.Scope(), unitFile: unitFile,
+		undeclared: undeclared.list()}, nil
+}
+
+// undeclaredIdentifiers collects the identifiers reported as undefined by the type-checker. The qualified identifiers
+// like strings.Join are skipped since they come from the empty packages of the stub importer.
+type undeclaredIdentifiers struct {
+	positions map[string]token.Position
+}
+
+func newUndeclaredIdentifiers() undeclaredIdentifiers {
+	return undeclaredIdentifiers{positions: make(map[string]token.Position)}
+}
+
+func (u undeclaredIdentifiers) add(err error) {
+	var typeErr types.Error
+	if !errors.As(err, &typeErr) {
+		return
+	}
+
+	name, found := strings.CutPrefix(typeErr.Msg, "undefined: ")
+	if !found || strings.Contains(name, ".") {
+		return
+	}
+
+	if _, ok := u.positions[name]; !ok {
+		u.positions[name] = typeErr.Fset.Position(typeErr.Pos)
+	}
+}
+
+// list returns the identifiers sorted by name along with their first position like 'NewPodmanCmdline (quadlet.go:12:8)'
+func (u undeclaredIdentifiers) list() []string {
+	identifiers := make([]string, 0, len(u.positions))
+	for _, name := range slices.Sorted(maps.Keys(u.positions)) {
+		identifiers = append(identifiers, fmt.Sprintf("%s (%s)", name, u.positions[name]))
+	}
+	return identifiers
+}
+
+func renamePackage(files []*ast.File, name string) {
+	for _, file := range files {
+		file.Name.Name = name
+	}
+}
+
+// stubImporter imports the parser package and replaces the other packages by empty ones
+type stubImporter struct {
+	parser   *types.Package
+	packages map[string]*types.Package
+}
+
+func (i *stubImporter) Import(importPath string) (*types.Package, error) {
+	if i.parser != nil && strings.HasSuffix(importPath, "/"+path.Dir(unitfileParserFilePath)) {
+		return i.parser, nil
+	}
+
+	if pkg, ok := i.packages[importPath]; ok {
+		return pkg, nil
+	}
+
+	pkg := types.NewPackage(importPath, path.Base(importPath))
+	pkg.MarkComplete()
+	i.packages[importPath] = pkg
+	return pkg, nil
+}
@@ -0,0 +1,203 @@
+package main
+
+import (
+	"go/token"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParsePackageFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	writeFile(t, filepath.Join(dir, "quadlet.go"), "package quadlet")
+	writeFile(t, filepath.Join(dir, "podmancmdline.go"), "package quadlet")
+	writeFile(t, filepath.Join(dir, "quadlet_test.go"), "package quadlet")
+	writeFile(t, filepath.Join(dir, "other.go"), "package other")
+	writeFile(t, filepath.Join(dir, "unitfile.go"), "package quadlet")
+
+	fset := token.NewFileSet()
+	files, err := parsePackageFiles(fset, filepath.Join(dir, "quadlet.go"), filepath.Join(dir, "unitfile.go"))
+	require.NoError(t, err)
+
+	names := make([]string, 0, len(files))
+	for _, file := range files {
+		names = append(names, filepath.Base(fset.Position(file.Pos()).Filename))
+	}
+	assert.Equal(t, []string{"quadlet.go", "podmancmdline.go"}, names)
+}
+
+// writeQuadletPackage writes a quadlet package along with the parser package of the test data in a directory
+func writeQuadletPackage(t *testing.T, source string) (string, string) {
+	t.Helper()
+
+	unitfile, err := os.ReadFile(unitfileTestFile)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+	quadletFile, unitfileFile := filepath.Join(dir, "quadlet.go"), filepath.Join(dir, "unitfile.go")
+	writeFile(t, quadletFile, source)
+	writeFile(t, unitfileFile, string(unitfile))
+	return quadletFile, unitfileFile
+}
+
+const valueFlowSource = `package quadlet
+
+import "github.com/containers/podman/v5/pkg/systemd/parser"
+
+const (
+	ContainerGroup   = "Container"
+	KeyImage         = "Image"
+	KeyLabel         = "Label"
+	KeyHealthCmd     = "HealthCmd"
+	KeyHealthRetries = "HealthRetries"
+)
+
+var supportedContainerKeys = map[string]bool{
+	KeyImage:         true,
+	KeyLabel:         true,
+	KeyHealthCmd:     true,
+	KeyHealthRetries: true,
+}
+
+func ConvertContainer(container *parser.UnitFile) error {
+	if err := checkForUnknownKeys(container, ContainerGroup, supportedContainerKeys); err != nil {
+		return err
+	}
+
+	stringKeys := map[string]string{
+		KeyImage: "--image",
+	}
+	lookupAndAddString(container, ContainerGroup, stringKeys)
+	container.LookupAllKeyVal(ContainerGroup, KeyLabel)
+
+	healthKeys := []struct {
+		key  string
+		flag string
+	}{
+		{key: KeyHealthCmd, flag: "--health-cmd"},
+		{key: KeyHealthRetries, flag: "--health-retries"},
+	}
+	for _, health := range healthKeys {
+		container.Lookup(ContainerGroup, health.key)
+	}
+	return nil
+}
+
+func checkForUnknownKeys(unit *parser.UnitFile, groupName string, supportedKeys map[string]bool) error {
+	return nil
+}
+
+func lookupAndAddString(unit *parser.UnitFile, group string, keys map[string]string) {
+	for key := range keys {
+		unit.LookupLast(group, key)
+	}
+}
+`
+
+func TestParseQuadletPackageFollowsValues(t *testing.T) {
+	t.Parallel()
+
+	lookupFuncs, err := parseUnitFileGo()
+	require.NoError(t, err)
+
+	quadletFile, unitfileFile := writeQuadletPackage(t, valueFlowSource)
+	fieldsByGroup, _, err := parseQuadletPackage(quadletFile, unitfileFile, lookupFuncs)
+	require.NoError(t, err)
+
+	lookups := make(map[string]string)
+	for _, field := range fieldsByGroup["Container"] {
+		lookups[field.Key] = field.LookupFunc.Name
+	}
+	assert.Equal(t, map[string]string{
+		"Image":         "LookupLast",
+		"Label":         "LookupAllKeyVal",
+		"HealthCmd":     "Lookup",
+		"HealthRetries": "Lookup",
+	}, lookups)
+}
+
+func TestParseQuadletPackageUnclassifiedKeys(t *testing.T) {
+	t.Parallel()
+
+	lookupFuncs, err := parseUnitFileGo()
+	require.NoError(t, err)
+
+	source := `package quadlet
+
+import "github.com/containers/podman/v5/pkg/systemd/parser"
+
+const (
+	ContainerGroup = "Container"
+	KeyImage       = "Image"
+	KeyLabel       = "Label"
+)
+
+var supportedContainerKeys = map[string]bool{
+	KeyImage: true,
+	KeyLabel: true,
+}
+
+func ConvertContainer(container *parser.UnitFile, keys []string) {
+	checkForUnknownKeys(container, ContainerGroup, supportedContainerKeys)
+	container.Lookup(ContainerGroup, KeyImage)
+	container.LookupAll(ContainerGroup, keys[len(keys)-1])
+}
+
+func checkForUnknownKeys(unit *parser.UnitFile, groupName string, supportedKeys map[string]bool) {}
+`
+	quadletFile, unitfileFile := writeQuadletPackage(t, source)
+	_, _, err = parseQuadletPackage(quadletFile, unitfileFile, lookupFuncs)
+	require.EqualError(t, err, "could not find the lookup function of 1 supported keys:\n"+
+		"  Container.Label\n"+
+		"the group or the key of these lookups could not be resolved:\n"+
+		"  "+quadletFile+":19:2")
+}
+
+func TestParseQuadletPackageUndeclaredIdentifiers(t *testing.T) {
+	t.Parallel()
+
+	lookupFuncs, err := parseUnitFileGo()
+	require.NoError(t, err)
+
+	// lookupAndAddBoolean is declared in another file of the package that is missing
+	source := `package quadlet
+
+import (
+	"strings"
+
+	"github.com/containers/podman/v5/pkg/systemd/parser"
+)
+
+const (
+	ContainerGroup = "Container"
+	KeyImage       = "Image"
+	KeyReadOnly    = "ReadOnly"
+)
+
+var supportedContainerKeys = map[string]bool{
+	KeyImage:    true,
+	KeyReadOnly: true,
+}
+
+func ConvertContainer(container *parser.UnitFile) {
+	checkForUnknownKeys(container, ContainerGroup, supportedContainerKeys)
+	image, _ := container.Lookup(ContainerGroup, KeyImage)
+	_ = strings.TrimSpace(image)
+	lookupAndAddBoolean(container, ContainerGroup, map[string]string{KeyReadOnly: "--read-only"})
+}
+
+func checkForUnknownKeys(unit *parser.UnitFile, groupName string, supportedKeys map[string]bool) {}
+`
+	quadletFile, unitfileFile := writeQuadletPackage(t, source)
+	_, _, err = parseQuadletPackage(quadletFile, unitfileFile, lookupFuncs)
+	require.EqualError(t, err, "could not find the lookup function of 1 supported keys:\n"+
+		"  Container.ReadOnly\n"+
+		"these identifiers are not declared by the loaded files of the quadlet package. Provide all its files with "+
+		"-source-dir flag:\n"+
+		"  lookupAndAddBoolean ("+quadletFile+":24:2)")
+}
@@ -0,0 +1,115 @@
+package lint
+
+import (
+	"io/fs"
+	"path"
+	"slices"
+
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/parser"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/validator"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/validator/common"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/validator/quadlet"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/validator/systemd"
+)
+
+var ParsingError = validator.ErrorCategory{
+	Name:  "parsing-error",
+	Level: validator.LevelError,
+}
+
+// Result of linting the unit files of a filesystem
+type Result struct {
+	// Paths of the linted unit files in the filesystem
+	Paths  []string
+	Errors validator.ValidationErrors
+}
+
+// Lint finds, parses and validates the unit files found at root in fsys. References between unit files are
+// resolved among the found unit files.
+func Lint(fsys fs.FS, root string, options validator.Options) (Result, error) {
+	paths, err := FindUnitFiles(fsys, root)
+	if err != nil {
+		return Result{}, err
+	}
+
+	unitFiles, parsingErrors := ParseUnitFiles(fsys, paths)
+	validationErrors := ValidateUnitFiles(unitFiles, options)
+
+	return Result{Paths: paths, Errors: validationErrors.Merge(parsingErrors)}, nil
+}
+
+// FindUnitFiles returns the paths of the unit files found at root in fsys. If root is a file, it is returned when it
+// has the extension of a unit file. Otherwise, it is walked and .git directories are skipped.
+func FindUnitFiles(fsys fs.FS, root string) ([]string, error) {
+	info, err := fs.Stat(fsys, root)
+	if err != nil {
+		return nil, err
+	}
+
+	if !info.IsDir() {
+		if !slices.Contains(model.AllUnitFileExtensions, path.Ext(root)) {
+			return []string{}, nil
+		}
+		return []string{root}, nil
+	}
+
+	unitFilesPaths := make([]string, 0)
+	err = fs.WalkDir(fsys, root, func(filePath string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if entry.IsDir() {
+			if entry.Name() == ".git" {
+				return fs.SkipDir
+			}
+			return nil
+		}
+
+		if slices.Contains(model.AllUnitFileExtensions, path.Ext(filePath)) {
+			unitFilesPaths = append(unitFilesPaths, filePath)
+		}
+
+		return nil
+	})
+
+	return unitFilesPaths, err
+}
+
+// ParseUnitFiles parses the unit files at paths in fsys and merges their drop-ins. Unit files with parsing errors
+// are returned along with their errors. Errors are keyed by the name of the unit file like the validation errors.
+func ParseUnitFiles(fsys fs.FS, paths []string) ([]model.UnitFile, validator.ValidationErrors) {
+	errors := make(validator.ValidationErrors)
+	unitFiles := make([]model.UnitFile, 0, len(paths))
+	for _, filePath := range paths {
+		unitFile, errs := parser.ParseWithDropIns(fsys, filePath)
+		if unitFile != nil {
+			unitFiles = append(unitFiles, unitFile)
+		}
+
+		for _, err := range errs {
+			validationError := ParsingError.Err("", err.Group, err.Key, err.Line, err.Column, err.Error())
+			validationError.FilePath = err.File
+			errors.AddError(path.Base(filePath), *validationError)
+		}
+	}
+	return unitFiles, errors
+}
+
+// ValidateUnitFiles runs every validator on the unit files
+func ValidateUnitFiles(unitFiles []model.UnitFile, options validator.Options) validator.ValidationErrors {
+	validationErrors := make(validator.ValidationErrors)
+	validators := []validator.Validator{
+		common.Validator(options),
+		quadlet.Validator(unitFiles, options),
+		systemd.Validator(unitFiles, options),
+	}
+
+	for _, file := range unitFiles {
+		for _, vtor := range validators {
+			validationErrors.AddError(file.FileName(), vtor.Validate(file)...)
+		}
+	}
+	return validationErrors
+}
@@ -0,0 +1,67 @@
+package lint
+
+import (
+	"io/fs"
+	"testing"
+	"testing/fstest"
+
+	"github.com/AhmedMoalla/quadlet-lint/pkg/validator"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/validator/quadlet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var testFS = fstest.MapFS{
+	"units/app.container":           {Data: []byte("[Container]\nImage=docker.io/library/app:1\nNetwork=app.network\n")},
+	"units/app.network":             {Data: []byte("[Network]\n")},
+	"units/app.container.d/ro.conf": {Data: []byte("[Container]\nReadOnly=maybe\n")},
+	"units/broken.pod":              {Data: []byte("[Pod]\nPodName\n")},
+	"units/nested/db.container":     {Data: []byte("[Container]\nImage=db\nNetwork=missing.network\n")},
+	"units/README.md":               {Data: []byte("# Units\n")},
+	"units/.git/old.container":      {Data: []byte("[Container]\n")},
+}
+
+func TestFindUnitFiles(t *testing.T) {
+	t.Parallel()
+
+	paths, err := FindUnitFiles(testFS, "units")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"units/app.container", "units/app.network", "units/broken.pod",
+		"units/nested/db.container"}, paths)
+
+	paths, err = FindUnitFiles(testFS, "units/app.network")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"units/app.network"}, paths)
+
+	paths, err = FindUnitFiles(testFS, "units/README.md")
+	require.NoError(t, err)
+	assert.Empty(t, paths)
+
+	_, err = FindUnitFiles(testFS, "missing")
+	require.ErrorIs(t, err, fs.ErrNotExist)
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	result, err := Lint(testFS, "units", validator.Options{CheckReferences: true})
+	require.NoError(t, err)
+	assert.Len(t, result.Paths, 4)
+
+	// The bad value is introduced by a drop-in of app.container
+	appErrors := result.Errors["app.container"]
+	require.Len(t, appErrors, 1)
+	assert.Equal(t, validator.InvalidValue, appErrors[0].ErrorCategory)
+	assert.Equal(t, validator.Location{FilePath: "units/app.container.d/ro.conf", Line: 2, Column: 9, EndLine: 2,
+		EndColumn: 14}, appErrors[0].Location)
+
+	// Parsing and validation errors of a unit file are under the same key
+	require.Len(t, result.Errors["broken.pod"], 1)
+	assert.Equal(t, ParsingError, result.Errors["broken.pod"][0].ErrorCategory)
+	assert.NotContains(t, result.Errors, "units/broken.pod")
+
+	dbErrors := result.Errors["db.container"]
+	require.Len(t, dbErrors, 2)
+	assert.Equal(t, quadlet.AmbiguousImageName, dbErrors[0].ErrorCategory)
+	assert.Equal(t, validator.InvalidReference, dbErrors[1].ErrorCategory)
+}
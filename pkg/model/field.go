@@ -2,18 +2,90 @@ package model
 
 import (
 	"fmt"
+	"slices"
+	"strings"
 
 	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/lookup"
 )
 
+// ValueKind is the kind of the values of a key
+type ValueKind string
+
+const (
+	KindString   ValueKind = "string"
+	KindBool     ValueKind = "bool"
+	KindInt      ValueKind = "int"
+	KindUint32   ValueKind = "uint32"
+	KindArgs     ValueKind = "args"
+	KindKeyValue ValueKind = "key-value"
+	// KindReference values can reference another unit by its file name like app.network
+	KindReference ValueKind = "reference"
+)
+
+// Multiplicity tells whether Quadlet uses every value of a key assigned several times or only the last one
+type Multiplicity string
+
+const (
+	MultiplicitySingle   Multiplicity = "single"
+	MultiplicityMultiple Multiplicity = "multiple"
+)
+
 type Field struct {
 	Group      string
 	Key        string
 	LookupFunc lookup.LookupFunc
+	// Kind is the kind of the values of the key
+	Kind         ValueKind
+	Multiplicity Multiplicity
+	// UnitTypes are the names of the unit types whose conversion uses the key
+	UnitTypes []string
+	// SpecifierPath is true when the value is a path resolved relative to the unit file unless it is absolute or
+	// starts with a systemd specifier like %h
+	SpecifierPath bool
+	// Since is the first Podman version supporting the key among the generated versions. It is empty when the key is
+	// supported by every generated version.
+	Since string
+	// Deprecated is true for the keys that Quadlet marks as deprecated
+	Deprecated bool
+	// Replacement is a hint about what replaces a deprecated key. It is empty when Quadlet does not give one.
+	Replacement string
+	// Doc is the documentation of the key. It is nil when the model was generated without the man page.
+	Doc *FieldDoc
 }
 
+// FieldDoc is the documentation of a key taken from the podman-systemd.unit man page
+type FieldDoc struct {
+	Description string
+	// Syntax is an example assignment of the key like AddCapability=CAP
+	Syntax   string
+	Default  string
+	Examples []string
+}
+
+// Multiple is true when every value of the key is used. The lookup function tells it when the multiplicity is not set.
 func (f Field) Multiple() bool {
-	return f.LookupFunc.Multiple
+	if f.Multiplicity == "" {
+		return f.LookupFunc.Multiple
+	}
+	return f.Multiplicity == MultiplicityMultiple
+}
+
+// AppliesTo is true when the key is used by the unit type. Every unit type is assumed when they are not known.
+func (f Field) AppliesTo(unitType UnitType) bool {
+	return len(f.UnitTypes) == 0 || slices.Contains(f.UnitTypes, unitType.Name)
+}
+
+// Summary returns the first sentence of the description of the key. It is empty when the key is not documented.
+func (f Field) Summary() string {
+	if f.Doc == nil {
+		return ""
+	}
+
+	description := f.Doc.Description
+	if end := strings.Index(description, ". "); end >= 0 {
+		return description[:end+1]
+	}
+	return description
 }
 
 func (f Field) String() string {
@@ -0,0 +1,41 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/lookup"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFieldMultiple(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Field{LookupFunc: lookup.LookupAll}.Multiple())
+	assert.False(t, Field{LookupFunc: lookup.Lookup}.Multiple())
+	// The multiplicity takes precedence over the lookup function. Only the last value of LookupLastArgs is used.
+	assert.False(t, Field{LookupFunc: lookup.LookupLastArgs, Multiplicity: MultiplicitySingle}.Multiple())
+	assert.True(t, Field{LookupFunc: lookup.Lookup, Multiplicity: MultiplicityMultiple}.Multiple())
+}
+
+func TestFieldAppliesTo(t *testing.T) {
+	t.Parallel()
+
+	field := Field{UnitTypes: []string{"container", "kube"}}
+	assert.True(t, field.AppliesTo(UnitTypeContainer))
+	assert.True(t, field.AppliesTo(UnitTypeKube))
+	assert.False(t, field.AppliesTo(UnitTypePod))
+	assert.True(t, Field{}.AppliesTo(UnitTypePod))
+}
+
+func TestFieldSummary(t *testing.T) {
+	t.Parallel()
+
+	assert.Empty(t, Field{}.Summary())
+	assert.Equal(t, "The image to run in the container.", Field{Doc: &FieldDoc{
+		Description: "The image to run in the container. It is recommended to use a fully qualified image name.",
+	}}.Summary())
+	// Dots that do not end a sentence are kept
+	assert.Equal(t, "Set the pull policy of quay.io images", Field{Doc: &FieldDoc{
+		Description: "Set the pull policy of quay.io images",
+	}}.Summary())
+}
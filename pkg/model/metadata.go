@@ -1,2 +1,13 @@
+// The model is generated for the newest supported version of Podman. Older versions are supported by generating only
+// their package with the versioned-only flag before it. The first version supporting each key of the model is computed
+// from them.
+
+//go:generate go run ../../cmd/quadlet-model-gen --podman-version v4.9.5 --versioned-only
+//go:generate go run ../../cmd/quadlet-model-gen --podman-version v5.0.3 --versioned-only
 //go:generate go run ../../cmd/quadlet-model-gen --podman-version v5.3.1
+
+// The model of the systemd directives accepted in the [Unit], [Service] and [Install] groups is generated in the
+// systemd package of the model from a list of directives. The gperf-file flag generates it from systemd's sources.
+//
+//go:generate go run ../../cmd/quadlet-model-gen systemd --systemd-version v256 --directives-file systemd.directives
 package model
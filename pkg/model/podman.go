@@ -0,0 +1,76 @@
+package model
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/AhmedMoalla/quadlet-lint/pkg/utils"
+)
+
+// PodmanVersion is a version of Podman whose Quadlet keys were generated by quadlet-model-gen
+type PodmanVersion struct {
+	// Name is the tag of the version like v5.3.1
+	Name string
+	// Fields are the keys supported in each group by the version
+	Fields map[string]map[string]Field
+}
+
+func (v PodmanVersion) String() string {
+	return v.Name
+}
+
+// HasField is true when the key of the field is supported in its group by the version
+func (v PodmanVersion) HasField(field Field) bool {
+	_, ok := v.Fields[field.Group][field.Key]
+	return ok
+}
+
+var (
+	podmanVersions      = make(map[string]PodmanVersion)
+	podmanVersionRegexp = regexp.MustCompile(`^v?\d+(\.\d+){0,2}$`)
+)
+
+// IsPodmanVersion is true for versions like v4.9.0, 4.9 or 5
+func IsPodmanVersion(version string) bool {
+	return podmanVersionRegexp.MatchString(version)
+}
+
+// RegisterPodmanVersion makes the keys of a version available to FindPodmanVersion. It is called by the generated
+// package of each version.
+func RegisterPodmanVersion(name string, fields map[string]map[string]Field) {
+	podmanVersions[name] = PodmanVersion{Name: name, Fields: fields}
+}
+
+// PodmanVersions returns the registered versions from the oldest to the newest
+func PodmanVersions() []PodmanVersion {
+	versions := make([]PodmanVersion, 0, len(podmanVersions))
+	for _, version := range podmanVersions {
+		versions = append(versions, version)
+	}
+	slices.SortFunc(versions, func(a, b PodmanVersion) int {
+		return utils.CompareVersions(a.Name, b.Name)
+	})
+	return versions
+}
+
+// FindPodmanVersion returns the newest registered version matching version. The v prefix is optional and version can
+// be partial: 4.9 matches the newest v4.9.x version and 5 matches the newest v5.x.y version.
+func FindPodmanVersion(version string) (PodmanVersion, error) {
+	wanted := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	versions := PodmanVersions()
+	for i := len(versions) - 1; i >= 0; i-- {
+		parts := strings.Split(strings.TrimPrefix(versions[i].Name, "v"), ".")
+		if len(parts) >= len(wanted) && slices.Equal(parts[:len(wanted)], wanted) {
+			return versions[i], nil
+		}
+	}
+
+	names := make([]string, 0, len(versions))
+	for _, v := range versions {
+		names = append(names, v.Name)
+	}
+	return PodmanVersion{}, fmt.Errorf("podman version '%s' is not supported. Supported versions: %s",
+		version, strings.Join(names, ", "))
+}
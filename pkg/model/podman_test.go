@@ -0,0 +1,62 @@
+package model
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindPodmanVersion(t *testing.T) {
+	for _, name := range []string{"v5.3.1", "v4.9.0", "v4.9.3", "v5.10.0", "v5.0.0"} {
+		RegisterPodmanVersion(name, map[string]map[string]Field{})
+	}
+
+	versions := make([]string, 0)
+	for _, version := range PodmanVersions() {
+		versions = append(versions, version.Name)
+	}
+	assert.Equal(t, []string{"v4.9.0", "v4.9.3", "v5.0.0", "v5.3.1", "v5.10.0"}, versions)
+
+	tests := map[string]string{
+		"v5.3.1": "v5.3.1",
+		"5.3.1":  "v5.3.1",
+		"5.3":    "v5.3.1",
+		"4.9":    "v4.9.3",
+		"4":      "v4.9.3",
+		"5":      "v5.10.0",
+		"5.0":    "v5.0.0",
+	}
+	for version, expected := range tests {
+		found, err := FindPodmanVersion(version)
+		require.NoError(t, err, version)
+		assert.Equal(t, expected, found.Name, version)
+	}
+
+	for _, version := range []string{"5.1", "4.9.1", "5.3.1.0", "6"} {
+		_, err := FindPodmanVersion(version)
+		require.ErrorContains(t, err, "Supported versions: v4.9.0, v4.9.3, v5.0.0, v5.3.1, v5.10.0", version)
+	}
+}
+
+func TestPodmanVersionHasField(t *testing.T) {
+	t.Parallel()
+
+	version := PodmanVersion{Name: "v4.9.0", Fields: map[string]map[string]Field{
+		"Container": {"Image": {Group: "Container", Key: "Image"}},
+	}}
+	assert.True(t, version.HasField(Field{Group: "Container", Key: "Image"}))
+	assert.False(t, version.HasField(Field{Group: "Container", Key: "Rootfs"}))
+	assert.False(t, version.HasField(Field{Group: "Pod", Key: "Image"}))
+}
+
+func TestIsPodmanVersion(t *testing.T) {
+	t.Parallel()
+
+	for _, version := range []string{"5", "4.9", "v4.9", "5.3.1", "v5.3.1"} {
+		assert.True(t, IsPodmanVersion(version), version)
+	}
+	for _, version := range []string{"", "v", "latest", "5.3.1.0", "5.x", "v5.4.0-rc1"} {
+		assert.False(t, IsPodmanVersion(version), version)
+	}
+}
@@ -1,5 +1,7 @@
 package model
 
+import "strings"
+
 var (
 	UnitTypeContainer = UnitType{Name: "container", Ext: ".container"}
 	UnitTypeVolume    = UnitType{Name: "volume", Ext: ".volume"}
@@ -10,6 +12,16 @@ var (
 	UnitTypePod       = UnitType{Name: "pod", Ext: ".pod"}
 )
 
+var AllUnitTypes = []UnitType{
+	UnitTypeContainer,
+	UnitTypeVolume,
+	UnitTypeKube,
+	UnitTypeNetwork,
+	UnitTypeImage,
+	UnitTypeBuild,
+	UnitTypePod,
+}
+
 var AllUnitFileExtensions = []string{
 	UnitTypeContainer.Ext,
 	UnitTypeVolume.Ext,
@@ -25,8 +37,12 @@ type UnitFile interface {
 	UnitType() UnitType
 	Lookup(field Field) (LookupResult, bool)
 	HasGroup(groupName string) bool
+	// GroupRange returns the range of the header of a group like [Container]
+	GroupRange(groupName string) (Range, bool)
 	ListGroups() []string
 	ListKeys(groupName string) []UnitKey
+	// ListAssignments lists every key-value pair of a group in the order of the file including empty assignments
+	ListAssignments(groupName string) []UnitValue
 	HasKey(field Field) bool
 	HasValue(field Field) bool
 }
@@ -36,9 +52,20 @@ type UnitType struct {
 	Ext  string
 }
 
+// Group returns the name of the group holding the Quadlet keys of the unit type. For example: Container
+func (t UnitType) Group() string {
+	if len(t.Name) == 0 {
+		return ""
+	}
+
+	return strings.ToUpper(t.Name[:1]) + t.Name[1:]
+}
+
 type UnitKey struct {
 	Key  string
 	Line int
+	// Range of the key name of its first occurrence
+	Range Range
 }
 
 type UnitValue struct {
@@ -46,14 +73,50 @@ type UnitValue struct {
 	Value  string
 	Line   int
 	Column int
+	// Range of the value in the file. Line and Column are its start.
+	Range Range
+	// KeyRange is the range of the key name of the assignment holding the value
+	KeyRange Range
+}
+
+// Position in a unit file. Lines start at 1 and columns at 0. Columns are in bytes and include indentation.
+type Position struct {
+	Line   int
+	Column int
+}
+
+// Range between two positions of a unit file. End is exclusive.
+type Range struct {
+	Start Position
+	End   Position
+	// File is the path of the drop-in holding the range. It is empty if the range is in the unit file itself.
+	File string
 }
 
 func (v UnitValue) String() string {
 	return v.Value
 }
 
+// LookupError is a value that a lookup could not convert. For example, because of an unbalanced quote, an invalid
+// escape sequence or a value that is not a boolean or a number.
+type LookupError struct {
+	Err error
+	// Range of the bad quote, escape sequence or value
+	Range Range
+}
+
+func (e LookupError) Error() string {
+	return e.Err.Error()
+}
+
+func (e LookupError) Unwrap() error {
+	return e.Err
+}
+
 type LookupResult interface {
 	Values() []UnitValue
+	// Errors lists the values that could not be converted. They are dropped or replaced by a default value.
+	Errors() []LookupError
 
 	IntValue() int
 	BoolValue() bool
@@ -0,0 +1,65 @@
+package model
+
+import (
+	"path"
+	"strings"
+)
+
+// UnitName is the name of a unit split in its parts. For web@blue.container, Prefix is web, Instance is blue and
+// Ext is .container.
+type UnitName struct {
+	Prefix   string
+	Instance string
+	Ext      string
+	// Templated is true for templates like web@.container and their instances like web@blue.container
+	Templated bool
+}
+
+// ParseUnitName splits the name of a unit like web@blue.container or other.service
+func ParseUnitName(name string) UnitName {
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	prefix, instance, templated := strings.Cut(stem, "@")
+	return UnitName{Prefix: prefix, Instance: instance, Ext: ext, Templated: templated}
+}
+
+// IsTemplate is true for templates like web@.container
+func (n UnitName) IsTemplate() bool {
+	return n.Templated && n.Instance == ""
+}
+
+// IsInstance is true for instances of a template like web@blue.container
+func (n UnitName) IsInstance() bool {
+	return n.Templated && n.Instance != ""
+}
+
+// Template returns the name of the template of the unit. For web@blue.container, it is web@.container.
+func (n UnitName) Template() string {
+	return n.Prefix + "@" + n.Ext
+}
+
+func (n UnitName) String() string {
+	if !n.Templated {
+		return n.Prefix + n.Ext
+	}
+
+	return n.Prefix + "@" + n.Instance + n.Ext
+}
+
+// FindUnitFile returns the unit file with the given name. An instance like web@blue.container that has no unit file
+// of its own is resolved to its template web@.container like systemd does.
+func FindUnitFile(units []UnitFile, name string) (UnitFile, bool) {
+	candidates := []string{name}
+	if unitName := ParseUnitName(name); unitName.IsInstance() {
+		candidates = append(candidates, unitName.Template())
+	}
+
+	for _, candidate := range candidates {
+		for _, unit := range units {
+			if unit.FileName() == candidate {
+				return unit, true
+			}
+		}
+	}
+	return nil, false
+}
@@ -0,0 +1,72 @@
+package parser
+
+import (
+	"errors"
+	"io/fs"
+	"maps"
+	"path"
+	"slices"
+	"strings"
+
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
+)
+
+const dropInExt = ".conf"
+
+// dropInDirs returns the drop-in directories of a unit file from the least to the most specific like systemd and
+// Quadlet do. For dir/foo-bar.container, they are:
+//   - dir/container.d which applies to every unit of the same type
+//   - dir/foo-.container.d which applies to every unit whose name starts with 'foo-'
+//   - dir/foo-bar.container.d which only applies to the unit
+//
+// An instance like dir/foo-bar@blue.container also has the drop-in directory of its template dir/foo-bar@.container.d
+// before its own.
+func dropInDirs(name string) []string {
+	dir, base := path.Split(name)
+	unitName := M.ParseUnitName(base)
+	ext := unitName.Ext
+
+	dirs := []string{strings.TrimPrefix(ext, ".") + ".d"}
+
+	parts := strings.Split(unitName.Prefix, "-")
+	for i := 1; i < len(parts); i++ {
+		dirs = append(dirs, strings.Join(parts[:i], "-")+"-"+ext+".d")
+	}
+
+	if unitName.IsInstance() {
+		dirs = append(dirs, unitName.Template()+".d")
+	}
+	dirs = append(dirs, base+".d")
+
+	for i := range dirs {
+		dirs[i] = path.Join(dir, dirs[i])
+	}
+	return dirs
+}
+
+// findDropIns returns the paths of the drop-ins of a unit file in the order they are merged. Drop-ins are the
+// *.conf files of the drop-in directories of the unit. They are sorted by file name whatever their directory and
+// a drop-in replaces the drop-ins with the same file name in less specific directories.
+func findDropIns(fsys fs.FS, name string) ([]string, error) {
+	dropInByName := make(map[string]string)
+	for _, dir := range dropInDirs(name) {
+		entries, err := fs.ReadDir(fsys, dir)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			if !entry.IsDir() && path.Ext(entry.Name()) == dropInExt {
+				dropInByName[entry.Name()] = path.Join(dir, entry.Name())
+			}
+		}
+	}
+
+	dropIns := make([]string, 0, len(dropInByName))
+	for _, dropInName := range slices.Sorted(maps.Keys(dropInByName)) {
+		dropIns = append(dropIns, dropInByName[dropInName])
+	}
+	return dropIns, nil
+}
@@ -0,0 +1,106 @@
+package parser
+
+import (
+	"testing"
+	"testing/fstest"
+
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/container"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDropInDirs(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, []string{"container.d", "app.container.d"}, dropInDirs("app.container"))
+	assert.Equal(t, []string{"units/container.d", "units/foo-.container.d", "units/foo-bar-.container.d",
+		"units/foo-bar-baz.container.d"}, dropInDirs("units/foo-bar-baz.container"))
+	assert.Equal(t, []string{"container.d", "foo-.container.d", "foo-bar@.container.d", "foo-bar@blue.container.d"},
+		dropInDirs("foo-bar@blue.container"))
+}
+
+var dropInsFS = fstest.MapFS{
+	"foo-bar.container":                    {Data: []byte("[Container]\nImage=base\nEnvironment=A=1\n")},
+	"container.d/10-env.conf":              {Data: []byte("[Container]\nEnvironment=B=2\n")},
+	"container.d/50-override.conf":         {Data: []byte("[Container]\nImage=type-wide\n")},
+	"foo-.container.d/20-reset.conf":       {Data: []byte("[Container]\nEnvironment=\nEnvironment=C=3\n")},
+	"foo-bar.container.d/50-override.conf": {Data: []byte("[Container]\n  Image=specific\n")},
+	"foo-bar.container.d/60-service.conf":  {Data: []byte("[Service]\nRestart=always\nbad line\n")},
+	"foo-bar.container.d/notes.txt":        {Data: []byte("not a drop-in")},
+	"other.container.d/10-other.conf":      {Data: []byte("[Container]\nImage=other\n")},
+}
+
+func TestFindDropIns(t *testing.T) {
+	t.Parallel()
+
+	dropIns, err := findDropIns(dropInsFS, "foo-bar.container")
+	require.NoError(t, err)
+	assert.Equal(t, []string{
+		"container.d/10-env.conf",
+		"foo-.container.d/20-reset.conf",
+		"foo-bar.container.d/50-override.conf",
+		"foo-bar.container.d/60-service.conf",
+	}, dropIns)
+
+	dropIns, err = findDropIns(dropInsFS, "missing.volume")
+	require.NoError(t, err)
+	assert.Empty(t, dropIns)
+}
+
+func TestParseWithDropIns(t *testing.T) {
+	t.Parallel()
+
+	unit, errs := ParseWithDropIns(dropInsFS, "foo-bar.container")
+	require.Len(t, errs, 1)
+	assert.Equal(t, "foo-bar.container.d/60-service.conf", errs[0].File)
+	assert.Equal(t, 3, errs[0].Line)
+
+	assert.Equal(t, "foo-bar.container", unit.FileName())
+	assert.Equal(t, []string{"Container", "Service"}, unit.ListGroups())
+
+	// The drop-in of the unit replaces the type-wide drop-in with the same name
+	res, ok := unit.Lookup(container.Image)
+	require.True(t, ok)
+	image, _ := res.Value()
+	assert.Equal(t, "specific", image.Value)
+	assert.Equal(t, M.Range{
+		Start: M.Position{Line: 2, Column: 8},
+		End:   M.Position{Line: 2, Column: 16},
+		File:  "foo-bar.container.d/50-override.conf",
+	}, image.Range)
+	assert.Equal(t, "foo-bar.container.d/50-override.conf", image.KeyRange.File)
+
+	// Drop-ins are merged by name so the reset of 20-reset.conf clears the values of the unit and of 10-env.conf
+	res, ok = unit.Lookup(container.Environment)
+	require.True(t, ok)
+	assert.Equal(t, []string{"C"}, utils.MapSlice(res.Values(), func(v M.UnitValue) string { return v.Key }))
+	assert.Equal(t, "foo-.container.d/20-reset.conf", res.Values()[0].Range.File)
+
+	header, ok := unit.GroupRange("Container")
+	require.True(t, ok)
+	assert.Empty(t, header.File)
+	header, ok = unit.GroupRange("Service")
+	require.True(t, ok)
+	assert.Equal(t, "foo-bar.container.d/60-service.conf", header.File)
+}
+
+func TestParseWithDropInsMissingFile(t *testing.T) {
+	t.Parallel()
+
+	unit, errs := ParseWithDropIns(dropInsFS, "missing.container")
+	assert.Nil(t, unit)
+	assert.Len(t, errs, 1)
+}
+
+func TestParseWithDropInsNotUnitFile(t *testing.T) {
+	t.Parallel()
+
+	fsys := fstest.MapFS{"Makefile": {Data: []byte("all:")}}
+	unit, errs := ParseWithDropIns(fsys, "Makefile")
+	assert.Nil(t, unit)
+	require.Len(t, errs, 1)
+	assert.Equal(t, "'Makefile' is not a unit file. Expected one of the extensions "+
+		"[.container .volume .kube .network .image .build .pod]", errs[0].Error())
+}
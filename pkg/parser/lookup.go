@@ -1,6 +1,10 @@
 package parser
 
 import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
 	"strconv"
 	"strings"
 	"unicode"
@@ -11,18 +15,18 @@ import (
 )
 
 type singleLookupFn = func(unitFile, M.Field) (unitValue, bool)
-type lookupFn = func(unitFile, M.Field) []unitValue
+type lookupFn = func(unitFile, M.Field) ([]unitValue, []M.LookupError)
 
 var lookupFuncs = map[lookup.LookupFunc]lookupFn{
 	lookup.Lookup:                   toMulti(unitFile.lookupBase),
 	lookup.LookupLast:               toMulti(unitFile.lookupLast),
 	lookup.LookupLastRaw:            toMulti(unitFile.lookupLastRaw),
-	lookup.LookupBoolean:            toMulti(unitFile.lookupBoolean),
-	lookup.LookupBooleanWithDefault: toMulti(unitFile.lookupBoolean),
-	lookup.LookupInt:                toMulti(unitFile.lookupInt),
-	lookup.LookupUint32:             toMulti(unitFile.lookupInt),
-	lookup.LookupAll:                unitFile.lookupAll,
-	lookup.LookupAllRaw:             unitFile.lookupAllRaw,
+	lookup.LookupBoolean:            unitFile.lookupBoolean,
+	lookup.LookupBooleanWithDefault: unitFile.lookupBoolean,
+	lookup.LookupInt:                unitFile.lookupInt,
+	lookup.LookupUint32:             unitFile.lookupUint32,
+	lookup.LookupAll:                withoutErrors(unitFile.lookupAll),
+	lookup.LookupAllRaw:             withoutErrors(unitFile.lookupAllRaw),
 	lookup.LookupAllStrv:            unitFile.lookupAllStrv,
 	lookup.LookupAllArgs:            unitFile.lookupAllArgs,
 	lookup.LookupAllKeyVal:          unitFile.lookupAllKeyVal,
@@ -30,16 +34,34 @@ var lookupFuncs = map[lookup.LookupFunc]lookupFn{
 }
 
 func toMulti(fn singleLookupFn) lookupFn {
-	return func(unit unitFile, field M.Field) []unitValue {
+	return func(unit unitFile, field M.Field) ([]unitValue, []M.LookupError) {
 		if val, ok := fn(unit, field); ok {
-			return []unitValue{val}
+			return []unitValue{val}, nil
 		}
-		return nil
+		return nil, nil
 	}
 }
 
+func withoutErrors(fn func(unitFile, M.Field) []unitValue) lookupFn {
+	return func(unit unitFile, field M.Field) ([]unitValue, []M.LookupError) {
+		return fn(unit, field), nil
+	}
+}
+
+// toLookupError converts an error of splitString to a lookup error located at the whole value if it does not
+// locate itself
+func toLookupError(err error, value unitValue) M.LookupError {
+	var lookupErr M.LookupError
+	if errors.As(err, &lookupErr) {
+		return lookupErr
+	}
+
+	return M.LookupError{Err: err, Range: value.toModel().Range}
+}
+
 type lookupResult struct {
 	values                []unitValue
+	errors                []M.LookupError
 	cachedInterfaceValues []M.UnitValue
 }
 
@@ -71,6 +93,10 @@ func (r *lookupResult) Value() (M.UnitValue, bool) {
 	return r.Values()[0], true
 }
 
+func (r *lookupResult) Errors() []M.LookupError {
+	return r.errors
+}
+
 func (r *lookupResult) Values() []M.UnitValue {
 	if r.cachedInterfaceValues == nil {
 		r.cachedInterfaceValues = utils.MapSlice(r.values, unitValue.toModel)
@@ -89,7 +115,8 @@ func (f unitFile) lookupLastRaw(field M.Field) (unitValue, bool) {
 	}
 
 	line, ok := g.findLast(field.Key)
-	if !ok {
+	if !ok || len(line.value.value) == 0 {
+		// An empty value resets the key to its default value
 		return unitValue{}, false
 	}
 
@@ -104,8 +131,7 @@ func (f unitFile) lookupLast(field M.Field) (unitValue, bool) {
 		return unitValue{}, false
 	}
 
-	raw.value = applyLineContinuation(raw.value)
-	return raw, true
+	return raw.applyLineContinuation(), true
 }
 
 // Look up the last instance of the named key in the group (if any)
@@ -121,46 +147,97 @@ func (f unitFile) lookupBase(field M.Field) (unitValue, bool) {
 	return v, true
 }
 
-// Lookup the last instance of a key and convert the value to a bool
-func (f unitFile) lookupBoolean(field M.Field) (unitValue, bool) {
+var (
+	errInvalidBoolean   = errors.New("invalid boolean")
+	errInvalidNumber    = errors.New("invalid number")
+	errNumberOutOfRange = errors.New("number out of range")
+	errNegativeNumber   = errors.New("negative number")
+)
+
+var trueValues = []string{"1", "yes", "true", "on"}
+var falseValues = []string{"0", "no", "false", "off"}
+
+// Lookup the last instance of a key and convert the value to a bool. A value that is not a boolean is converted to
+// false and reported as an error.
+func (f unitFile) lookupBoolean(field M.Field) ([]unitValue, []M.LookupError) {
 	v, ok := f.lookupBase(field)
 	if !ok {
-		return unitValue{value: "false", boolValue: false, line: v.line, valueColumn: v.valueColumn}, false
+		return nil, nil
+	}
+
+	var errs []M.LookupError
+	isValue := func(value string) bool { return strings.EqualFold(value, v.value) }
+	if !slices.ContainsFunc(trueValues, isValue) && !slices.ContainsFunc(falseValues, isValue) {
+		errs = append(errs, typeError(v, fmt.Errorf("%w '%s'. Allowed values: %s", errInvalidBoolean, v.value,
+			append(trueValues, falseValues...))))
+	}
+
+	v.boolValue = slices.ContainsFunc(trueValues, isValue)
+	v.value = strconv.FormatBool(v.boolValue)
+	return []unitValue{v}, errs
+}
+
+// Lookup the last instance of a key and convert the value to an int. A value that is not a number is dropped and
+// reported as an error.
+func (f unitFile) lookupInt(field M.Field) ([]unitValue, []M.LookupError) {
+	v, intVal, errs := f.lookupNumber(field)
+	if v == nil {
+		return nil, errs
+	}
+
+	v.intValue = int(intVal)
+	return []unitValue{*v}, nil
+}
+
+// Lookup the last instance of a key and convert the value to an uint32. A value that is not a number or does not
+// fit in an uint32 is dropped and reported as an error.
+func (f unitFile) lookupUint32(field M.Field) ([]unitValue, []M.LookupError) {
+	v, intVal, errs := f.lookupNumber(field)
+	if v == nil {
+		return nil, errs
 	}
 
-	value := v.value
-	boolValue := strings.EqualFold(value, "1") ||
-		strings.EqualFold(value, "yes") ||
-		strings.EqualFold(value, "true") ||
-		strings.EqualFold(value, "on")
-	return unitValue{
-		key:         field.Key,
-		value:       strconv.FormatBool(boolValue),
-		boolValue:   boolValue,
-		line:        v.line,
-		valueColumn: v.valueColumn,
-	}, true
+	// The range is checked on the int64 since an int cannot hold the maximum uint32 on 32-bit platforms
+	switch {
+	case intVal < 0:
+		return nil, []M.LookupError{typeError(*v, fmt.Errorf("%w '%s'", errNegativeNumber, v.value))}
+	case intVal > math.MaxUint32:
+		return nil, []M.LookupError{typeError(*v, fmt.Errorf("%w '%s'. The maximum is %d", errNumberOutOfRange,
+			v.value, uint32(math.MaxUint32)))}
+	default:
+		v.intValue = int(intVal)
+		return []unitValue{*v}, nil
+	}
 }
 
-// Lookup the last instance of a key and convert the value to an int
-func (f unitFile) lookupInt(field M.Field) (unitValue, bool) {
+// lookupNumber looks up the last instance of a key and converts the value to an int64. The value is nil when the key
+// is not found or when its value is not a number.
+func (f unitFile) lookupNumber(field M.Field) (*unitValue, int64, []M.LookupError) {
 	v, ok := f.lookupBase(field)
 	if !ok {
-		return unitValue{}, false
+		return nil, 0, nil
 	}
 
 	intVal, err := convertNumber(v.value)
 	if err != nil {
-		return unitValue{}, false
+		return nil, 0, []M.LookupError{typeError(v, numberError(v.value, err))}
+	}
+
+	v.value = strconv.FormatInt(intVal, 10)
+	return &v, intVal, nil
+}
+
+func numberError(value string, err error) error {
+	if errors.Is(err, strconv.ErrRange) {
+		return fmt.Errorf("%w '%s'", errNumberOutOfRange, value)
 	}
 
-	return unitValue{
-		key:         field.Key,
-		value:       strconv.FormatInt(intVal, 10),
-		line:        v.line,
-		valueColumn: v.valueColumn,
-		intValue:    int(intVal),
-	}, true
+	return fmt.Errorf("%w '%s'", errInvalidNumber, value)
+}
+
+// typeError is an error concerning the whole value
+func typeError(v unitValue, err error) M.LookupError {
+	return M.LookupError{Err: err, Range: v.toModel().Range}
 }
 
 // Look up every instance of the named key in the group
@@ -180,7 +257,6 @@ func (f unitFile) lookupAllRaw(field M.Field) []unitValue {
 				// Empty value clears all before
 				values = make([]unitValue, 0)
 			} else {
-				line.value.line -= strings.Count(line.value.value, "\n")
 				values = append(values, line.value)
 			}
 		}
@@ -194,7 +270,7 @@ func (f unitFile) lookupAllRaw(field M.Field) []unitValue {
 func (f unitFile) lookupAll(field M.Field) []unitValue {
 	values := f.lookupAllRaw(field)
 	for i, raw := range values {
-		values[i].value = applyLineContinuation(raw.value)
+		values[i] = raw.applyLineContinuation()
 	}
 	return values
 }
@@ -203,29 +279,37 @@ func (f unitFile) lookupAll(field M.Field) []unitValue {
 // separated words (including handling quoted words) and combine them all into
 // one array of words. The split code is compatible with the systemd config_parse_strv().
 // This is typically used by systemd keys like "RequiredBy" and "Aliases".
-func (f unitFile) lookupAllStrv(field M.Field) []unitValue {
+func (f unitFile) lookupAllStrv(field M.Field) ([]unitValue, []M.LookupError) {
 	values := f.lookupAll(field)
 	res := make([]unitValue, 0, len(values))
+	errs := make([]M.LookupError, 0)
 	for _, value := range values {
-		res, _ = splitValueAppend(res, value, WhitespaceSeparators, SplitRetainEscape|SplitUnquote)
+		var err error
+		res, err = splitValueAppend(res, value, WhitespaceSeparators, SplitRetainEscape|SplitUnquote)
+		if err != nil {
+			errs = append(errs, toLookupError(err, value))
+		}
 	}
-	return res
+	return res, errs
 }
 
 // Look up every instance of the named key in the group, and for each, split space
 // separated words (including handling quoted words) and combine them all into
 // one array of words. The split code is exec-like, and both unquotes and applied
 // c-style c escapes.
-func (f unitFile) lookupAllArgs(field M.Field) []unitValue {
+func (f unitFile) lookupAllArgs(field M.Field) ([]unitValue, []M.LookupError) {
 	res := make([]unitValue, 0)
+	errs := make([]M.LookupError, 0)
 	argsv := f.lookupAll(field)
 	for _, argsS := range argsv {
 		args, err := splitString(argsS, WhitespaceSeparators, SplitRelax|SplitUnquote|SplitCUnescape)
-		if err == nil {
-			res = append(res, args...)
+		if err != nil {
+			errs = append(errs, toLookupError(err, argsS))
+			continue
 		}
+		res = append(res, args...)
 	}
-	return res
+	return res, errs
 }
 
 // Look up last instance of the named key in the group, and split
@@ -233,82 +317,63 @@ func (f unitFile) lookupAllArgs(field M.Field) []unitValue {
 // array of words. The split code is exec-like, and both unquotes and
 // applied c-style c escapes.  This is typically used for keys like
 // ExecStart
-func (f unitFile) lookupLastArgs(field M.Field) []unitValue {
+func (f unitFile) lookupLastArgs(field M.Field) ([]unitValue, []M.LookupError) {
 	execKey, ok := f.lookupLast(field)
 	if ok {
 		execArgs, err := splitString(execKey, WhitespaceSeparators, SplitRelax|SplitUnquote|SplitCUnescape)
-		if err == nil {
-			return execArgs
+		if err != nil {
+			return nil, []M.LookupError{toLookupError(err, execKey)}
 		}
+		return execArgs, nil
 	}
-	return nil
+	return nil, nil
 }
 
 // Look up 'Environment' style key-value keys
-func (f unitFile) lookupAllKeyVal(field M.Field) []unitValue {
+func (f unitFile) lookupAllKeyVal(field M.Field) ([]unitValue, []M.LookupError) {
 	res := make([]unitValue, 0)
+	errs := make([]M.LookupError, 0)
 	allKeyvals := f.lookupAll(field)
 	for _, keyvals := range allKeyvals {
 		assigns, err := splitString(keyvals, WhitespaceSeparators, SplitRelax|SplitUnquote|SplitCUnescape)
-		if err == nil {
-			for _, assign := range assigns {
-				key, value, found := strings.Cut(assign.value, "=")
-				if found {
-					res = append(res, unitValue{
-						key:         key,
-						value:       value,
-						line:        assign.line,
-						valueColumn: assigns[0].valueColumn,
-					})
-				}
+		if err != nil {
+			errs = append(errs, toLookupError(err, keyvals))
+			continue
+		}
+
+		for _, assign := range assigns {
+			key, value, found := strings.Cut(assign.value, "=")
+			if found {
+				// The range of the value is the range of the whole assignment
+				assign.key = key
+				assign.value = value
+				res = append(res, assign)
 			}
 		}
 	}
-	return res
+	return res, errs
 }
 
 /* Mimics strol, which is what systemd uses. */
 func convertNumber(v string) (int64, error) {
-	var err error
-	var intVal int64
-
-	mult := int64(1)
-
-	if strings.HasPrefix(v, "+") {
-		v = v[1:]
-	} else if strings.HasPrefix(v, "-") {
-		v = v[1:]
-		mult = int64(-11)
+	sign := ""
+	if strings.HasPrefix(v, "+") || strings.HasPrefix(v, "-") {
+		sign, v = v[:1], v[1:]
 	}
 
+	base := 10
 	switch {
 	case strings.HasPrefix(v, "0x") || strings.HasPrefix(v, "0X"):
-		intVal, err = strconv.ParseInt(v[2:], 16, 64)
+		base, v = 16, v[2:]
 	case strings.HasPrefix(v, "0"):
-		intVal, err = strconv.ParseInt(v, 8, 64)
-	default:
-		intVal, err = strconv.ParseInt(v, 10, 64)
+		base = 8
 	}
 
-	return intVal * mult, err
-}
-
-func applyLineContinuation(raw string) string {
-	if !strings.Contains(raw, "\\\n") {
-		return raw
-	}
-
-	var str strings.Builder
-
-	for len(raw) > 0 {
-		if first, rest, found := strings.Cut(raw, "\\\n"); found {
-			str.WriteString(first)
-			raw = rest
-		} else {
-			str.WriteString(raw)
-			raw = ""
-		}
+	// The sign was already consumed so another one is invalid
+	if strings.HasPrefix(v, "+") || strings.HasPrefix(v, "-") {
+		return 0, strconv.ErrSyntax
 	}
 
-	return str.String()
+	// The sign is parsed along with the digits so that the minimum int64 does not overflow
+	return strconv.ParseInt(sign+v, base, 64)
 }
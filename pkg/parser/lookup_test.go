@@ -1,9 +1,12 @@
 package parser
 
 import (
+	"math"
 	"testing"
 
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
 	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/container"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/utils"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -26,3 +29,149 @@ Exec=/some/path "an arg" "a;b\nc\td'e" a;b\nc\td 'a"b' '\110ello \127orld'`
 		assert.Equal(t, expected[i], res.Values()[i].Value)
 	}
 }
+
+func TestLookupEmptyValueResetsKey(t *testing.T) {
+	t.Parallel()
+
+	content := `[Container]
+Image=first
+Image=
+DNS=1.1.1.1
+DNS=
+DNS=8.8.8.8
+DropCapability=ALL
+DropCapability=`
+
+	unit, err := ParseUnitFileString("test.container", content)
+	require.Empty(t, err)
+
+	_, ok := unit.Lookup(container.Image)
+	assert.False(t, ok)
+	assert.False(t, unit.HasKey(container.Image))
+
+	res, ok := unit.Lookup(container.DNS)
+	assert.True(t, ok)
+	assert.Equal(t, []M.UnitValue{onLine(M.UnitValue{Key: "DNS", Value: "8.8.8.8", Line: 6, Column: 4})}, res.Values())
+
+	_, ok = unit.Lookup(container.DropCapability)
+	assert.False(t, ok)
+
+	assert.Len(t, unit.ListAssignments("Container"), 7)
+}
+
+func TestLookupReportsSplitErrors(t *testing.T) {
+	t.Parallel()
+
+	content := `[Container]
+Environment=A=1 \
+  B=\x4g
+Environment=C=2
+AddCapability=CAP_A 'CAP_B
+Exec=/bin/sh -c "echo \q"`
+
+	unit, err := ParseUnitFileString("test.container", content)
+	require.Empty(t, err)
+
+	res, ok := unit.Lookup(container.Environment)
+	assert.True(t, ok)
+	require.Len(t, res.Values(), 1)
+	assert.Equal(t, "C", res.Values()[0].Key)
+	require.Len(t, res.Errors(), 1)
+	require.ErrorIs(t, res.Errors()[0], errUnsupportedEscapeChar)
+	assert.Equal(t, M.Range{Start: M.Position{Line: 3, Column: 4}, End: M.Position{Line: 3, Column: 6}},
+		res.Errors()[0].Range)
+
+	res, ok = unit.Lookup(container.AddCapability)
+	assert.False(t, ok)
+	require.Len(t, res.Errors(), 1)
+	require.ErrorIs(t, res.Errors()[0], errUnbalancedQuotes)
+	assert.Equal(t, M.Range{Start: M.Position{Line: 5, Column: 20}, End: M.Position{Line: 5, Column: 21}},
+		res.Errors()[0].Range)
+
+	res, ok = unit.Lookup(container.Exec)
+	assert.False(t, ok)
+	require.Len(t, res.Errors(), 1)
+	assert.Equal(t, M.Range{Start: M.Position{Line: 6, Column: 22}, End: M.Position{Line: 6, Column: 24}},
+		res.Errors()[0].Range)
+}
+
+func TestConvertNumber(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		input    string
+		expected int64
+		valid    bool
+	}{
+		{"42", 42, true},
+		{"+42", 42, true},
+		{"-42", -42, true},
+		{"0x1F", 31, true},
+		{"-0x1F", -31, true},
+		{"017", 15, true},
+		{"-017", -15, true},
+		{"0", 0, true},
+		{"-9223372036854775808", math.MinInt64, true},
+		{"9223372036854775808", 0, false},
+		{"12k", 0, false},
+		{"--1", 0, false},
+		{"+-1", 0, false},
+		{"0x-1", 0, false},
+		{"", 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.input, func(t *testing.T) {
+			t.Parallel()
+
+			value, err := convertNumber(test.input)
+			if test.valid {
+				require.NoError(t, err)
+				assert.Equal(t, test.expected, value)
+			} else {
+				require.Error(t, err)
+			}
+		})
+	}
+}
+
+func TestLookupReportsTypeErrors(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		field    M.Field
+		values   []string
+		expected error
+	}{
+		{"InvalidBoolean", "ReadOnly=maybe", container.ReadOnly, []string{"false"}, errInvalidBoolean},
+		{"ValidBoolean", "ReadOnly=Yes", container.ReadOnly, []string{"true"}, nil},
+		{"InvalidNumber", "RemapUidSize=12k", container.RemapUidSize, nil, errInvalidNumber},
+		{"NegativeUint32", "RemapUidSize=-1", container.RemapUidSize, nil, errNegativeNumber},
+		{"Uint32Overflow", "RemapUidSize=4294967296", container.RemapUidSize, nil, errNumberOutOfRange},
+		{"Uint32OverflowHex", "RemapUidSize=0x100000000", container.RemapUidSize, nil, errNumberOutOfRange},
+		{"Int64Overflow", "RemapUidSize=0xFFFFFFFFFFFFFFFFF", container.RemapUidSize, nil, errNumberOutOfRange},
+		{"MaxUint32", "RemapUidSize=4294967295", container.RemapUidSize, []string{"4294967295"}, nil},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			unit, err := ParseUnitFileString("test.container", "[Container]\n"+test.content)
+			require.Empty(t, err)
+
+			res, _ := unit.Lookup(test.field)
+			assert.ElementsMatch(t, test.values, utils.MapSlice(res.Values(), M.UnitValue.String))
+			if test.expected == nil {
+				assert.Empty(t, res.Errors())
+				return
+			}
+
+			require.Len(t, res.Errors(), 1)
+			require.ErrorIs(t, res.Errors()[0], test.expected)
+			assert.Equal(t, 2, res.Errors()[0].Range.Start.Line)
+		})
+	}
+}
@@ -2,6 +2,8 @@ package parser
 
 import (
 	"fmt"
+	"io"
+	"io/fs"
 	"os"
 	"path"
 	"strings"
@@ -12,6 +14,8 @@ import (
 
 type unitFileParser struct {
 	file *unitFile
+	// source is the path of the drop-in being parsed. It is empty when parsing the unit file itself.
+	source string
 
 	currentGroup *unitGroup
 	lineNr       int
@@ -24,6 +28,8 @@ type ParsingError struct {
 	Column  int
 	Group   string
 	Key     string
+	// File is the path of the drop-in where the error is located. It is empty for errors in the unit file itself.
+	File string
 }
 
 func newParsingError(line int, column int, group, key, message string) *ParsingError {
@@ -48,60 +54,124 @@ func (e *ParsingError) Error() string {
 	return e.message
 }
 
+func (e *ParsingError) Unwrap() error {
+	return e.inner
+}
+
 // Load a unit file from disk, remembering the path and filename
 func ParseUnitFile(pathName string) (M.UnitFile, []ParsingError) {
-	data, e := os.ReadFile(pathName)
+	file, e := os.Open(pathName)
+	if e != nil {
+		return nil, []ParsingError{{inner: e}}
+	}
+	defer file.Close()
+
+	return Parse(file, pathName)
+}
+
+// ParseFS loads a unit file from a filesystem like an embed.FS. name is a path in the filesystem.
+func ParseFS(fsys fs.FS, name string) (M.UnitFile, []ParsingError) {
+	file, e := fsys.Open(name)
+	if e != nil {
+		return nil, []ParsingError{{inner: e}}
+	}
+	defer file.Close()
+
+	return Parse(file, name)
+}
+
+// Parse reads a unit file from r. The name and the type of the unit file are taken from name which is usually its
+// path.
+func Parse(r io.Reader, name string) (M.UnitFile, []ParsingError) {
+	data, e := io.ReadAll(r)
 	if e != nil {
 		return nil, []ParsingError{{inner: e}}
 	}
 
-	return ParseUnitFileString(pathName, string(data))
+	return ParseUnitFileString(name, string(data))
 }
 
+// ParseUnitFileString parses the content of a unit file. Lines that cannot be parsed are reported as errors and
+// skipped so the returned unit file holds everything that could be parsed even when errors are returned.
 func ParseUnitFileString(pathName, content string) (M.UnitFile, []ParsingError) {
+	f, err := newUnitFileAt(pathName)
+	if err != nil {
+		return nil, []ParsingError{*err}
+	}
+
+	parsingErrors := parse(&f, content, "")
+
+	return f, parsingErrors
+}
+
+// ParseWithDropIns loads a unit file from a filesystem and merges its drop-ins into it. See findDropIns for where
+// drop-ins are looked up and in which order they are merged. Values and errors coming from a drop-in are located
+// in it.
+func ParseWithDropIns(fsys fs.FS, name string) (M.UnitFile, []ParsingError) {
+	data, e := fs.ReadFile(fsys, name)
+	if e != nil {
+		return nil, []ParsingError{{inner: e}}
+	}
+
+	f, err := newUnitFileAt(name)
+	if err != nil {
+		return nil, []ParsingError{*err}
+	}
+
+	parsingErrors := parse(&f, string(data), "")
+
+	dropIns, e := findDropIns(fsys, name)
+	if e != nil {
+		parsingErrors = append(parsingErrors, ParsingError{inner: e})
+	}
+
+	for _, dropIn := range dropIns {
+		data, e := fs.ReadFile(fsys, dropIn)
+		if e != nil {
+			parsingErrors = append(parsingErrors, ParsingError{inner: e, File: dropIn})
+			continue
+		}
+
+		parsingErrors = append(parsingErrors, parse(&f, string(data), dropIn)...)
+	}
+
+	return f, parsingErrors
+}
+
+// newUnitFileAt creates an empty unit file whose type is given by the extension of pathName. An error is returned
+// when the extension is not the one of a Quadlet unit file.
+func newUnitFileAt(pathName string) (unitFile, *ParsingError) {
 	filename := path.Base(pathName)
 	ext := path.Ext(pathName)
-	unitType := M.UnitType{Name: ext[1:], Ext: ext}
-	f := newUnitFile(filename, unitType)
-
-	parsingErrors := parse(&f, content)
-	if len(parsingErrors) > 0 {
-		return nil, parsingErrors
+	for _, unitType := range M.AllUnitTypes {
+		if unitType.Ext == ext {
+			return newUnitFile(filename, unitType), nil
+		}
 	}
 
-	return f, []ParsingError{}
+	return unitFile{}, newParsingErrorAtLine(0, "", "", fmt.Sprintf("'%s' is not a unit file. Expected one of "+
+		"the extensions %s", filename, M.AllUnitFileExtensions))
 }
 
-// parse an already loaded unit file (in the form of a string)
-func parse(f *unitFile, data string) []ParsingError {
+// parse an already loaded unit file (in the form of a string). source is the path of the drop-in when data is the
+// content of a drop-in that is merged into f.
+func parse(f *unitFile, data string, source string) []ParsingError {
 	p := &unitFileParser{
 		file:   f,
+		source: source,
 		lineNr: 0,
 	}
 
-	data = trimSpacesFromLines(data)
-
 	parsingErrors := make([]ParsingError, 0)
-	for len(data) > 0 {
-		origdata := data
-		var line string
-		line, data = nextLine(data, 0)
-		p.lineNr++
+	for _, node := range ParseSyntaxTree(data).Nodes {
+		p.lineNr = node.Line
 
-		if lineIsComment(line) {
+		if node.Kind == BlankNode || node.Kind == CommentNode {
 			continue
 		}
 
-		// Handle multi-line continuations
-		// Note: This doesn't support comments in the middle of the continuation, which systemd does
-		if lineIsKeyValuePair(line) {
-			for len(data) > 0 && line[len(line)-1] == '\\' {
-				line, data = nextLine(origdata, len(line)+1)
-				p.lineNr++
-			}
-		}
-
-		if err := p.parseLine(line); err != nil {
+		if err := p.parseNode(node); err != nil {
+			err.File = source
 			parsingErrors = append(parsingErrors, *err)
 		}
 	}
@@ -109,45 +179,48 @@ func parse(f *unitFile, data string) []ParsingError {
 	return parsingErrors
 }
 
-func nextLine(data string, afterPos int) (string, string) {
-	rest := data[afterPos:]
-	if i := strings.Index(rest, "\n"); i >= 0 {
-		return strings.TrimSpace(data[:i+afterPos]), data[i+afterPos+1:]
-	}
-	return data, ""
-}
-
-func (p *unitFileParser) parseLine(line string) *ParsingError {
-	switch {
-	case lineIsGroup(line):
-		return p.parseGroup(line)
-	case lineIsKeyValuePair(line):
-		return p.parseKeyValuePair(line)
+func (p *unitFileParser) parseNode(node *Node) *ParsingError {
+	switch node.Kind {
+	case GroupNode:
+		return p.parseGroup(node.lines()[0])
+	case KeyValueNode:
+		return p.parseKeyValuePair(node.lines())
 	default:
+		line := node.Text()
 		return newParsingErrorAtLine(p.lineNr, p.currentGroup.String(), line,
 			fmt.Sprintf("“%s” is not a key-value pair or group", line))
 	}
 }
 
-func (p *unitFileParser) parseGroup(line string) *ParsingError {
-	end := strings.Index(line, "]")
+func (p *unitFileParser) parseGroup(line nodeLine) *ParsingError {
+	end := strings.Index(line.text, "]")
 
-	groupName := line[1:end]
+	groupName := line.text[1:end]
 
 	if valid, badIndex := groupNameIsValid(groupName); !valid {
-		return newParsingError(p.lineNr, badIndex+1, groupName, "", "invalid group name: "+groupName)
+		// Keys of an invalid group are collected in a group that is not part of the file
+		// so that they are not added to the previous group
+		p.currentGroup = newUnitGroup(groupName)
+		return newParsingError(p.lineNr, line.indent+badIndex+1, groupName, "", "invalid group name: "+groupName)
 	}
 
-	p.currentGroup = ensureGroup(p.file, groupName)
+	p.currentGroup = ensureGroup(p.file, groupName, M.Range{
+		Start: M.Position{Line: p.lineNr, Column: line.indent},
+		End:   M.Position{Line: p.lineNr, Column: line.indent + end + 1},
+		File:  p.source,
+	})
 
 	return nil
 }
 
-func (p *unitFileParser) parseKeyValuePair(line string) *ParsingError {
+// parseKeyValuePair parses the lines of a key-value pair. Lines after the first one are line continuations.
+func (p *unitFileParser) parseKeyValuePair(lines []nodeLine) *ParsingError {
 	if p.currentGroup == nil {
 		return newParsingErrorAtLine(p.lineNr, "", "", "key file does not start with a group")
 	}
 
+	first := lines[0]
+	line := first.text
 	keyEnd := strings.Index(line, "=")
 	valueStart := keyEnd + 1
 
@@ -157,7 +230,7 @@ func (p *unitFileParser) parseKeyValuePair(line string) *ParsingError {
 	}
 	key := line[:keyEnd]
 	if valid, badIndex := keyNameIsValid(key); !valid {
-		return newParsingError(p.lineNr, badIndex, p.currentGroup.name, key, "invalid key name: "+key)
+		return newParsingError(p.lineNr, first.indent+badIndex, p.currentGroup.name, key, "invalid key name: "+key)
 	}
 
 	// Pull the value from the line (chugging leading whitespace)
@@ -168,27 +241,46 @@ func (p *unitFileParser) parseKeyValuePair(line string) *ParsingError {
 
 	value := line[valueStart:]
 
-	if len(value) == 0 {
-		return newParsingError(p.lineNr, valueStart, p.currentGroup.name, key,
-			fmt.Sprintf("key '%s' in group '%s' has an empty value", key, p.currentGroup.name))
+	// Remember where every continuation line starts in the value to locate the words on those lines
+	var segments []valueSegment
+	for _, continuation := range lines[1:] {
+		segments = append(segments, valueSegment{
+			offset: len(value) + 1,
+			line:   continuation.number,
+			column: continuation.indent,
+		})
+		value += "\n" + continuation.text
 	}
 
-	p.currentGroup.add(key, unitValue{
+	unitValue := unitValue{
 		key:         key,
 		value:       value,
 		line:        p.lineNr,
-		valueColumn: valueStart,
-	})
+		valueColumn: first.indent + valueStart,
+		file:        p.source,
+		segments:    segments,
+		keyRange: M.Range{
+			Start: M.Position{Line: p.lineNr, Column: first.indent},
+			End:   M.Position{Line: p.lineNr, Column: first.indent + keyEnd},
+			File:  p.source,
+		},
+	}
+	unitValue.end = unitValue.positionOf(len(value))
+
+	// An empty value is kept because it resets the values assigned before it
+	p.currentGroup.add(key, unitValue)
 
 	return nil
 }
 
-func ensureGroup(f *unitFile, groupName string) *unitGroup {
+// ensureGroup returns the group with the given name. A group appearing several times is located at its first header.
+func ensureGroup(f *unitFile, groupName string, header M.Range) *unitGroup {
 	if g, ok := f.groupByName[groupName]; ok {
 		return g
 	}
 
 	g := newUnitGroup(groupName)
+	g.header = header
 	f.groups = append(f.groups, g)
 	f.groupByName[groupName] = g
 
@@ -279,11 +371,3 @@ func keyNameIsValid(name string) (bool, int) {
 
 	return true, -1
 }
-
-func trimSpacesFromLines(data string) string {
-	lines := strings.Split(data, "\n")
-	for i, line := range lines {
-		lines[i] = strings.TrimSpace(line)
-	}
-	return strings.Join(lines, "\n")
-}
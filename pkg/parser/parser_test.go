@@ -1,8 +1,11 @@
 package parser
 
 import (
+	"io/fs"
 	"os"
+	"strings"
 	"testing"
+	"testing/fstest"
 
 	. "github.com/AhmedMoalla/quadlet-lint/pkg/model"
 	generated "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
@@ -22,12 +25,10 @@ func TestParseUnitFileErrors(t *testing.T) {
 	require.ErrorIs(t, errors[0].inner, os.ErrNotExist)
 
 	file, errors = ParseUnitFile("testdata/err.container")
-	assert.Nil(t, file)
+	require.NotNil(t, file)
 
 	expectedErrs := []ParsingError{
 		{Group: "", Key: "", Line: 1, Column: 0},
-		{Group: "Container", Key: "ContainerName", Line: 5, Column: 14},
-		{Group: "Service", Key: "Bla", Line: 14, Column: 4},
 		{Group: "Install", Key: "afaffazf", Line: 20, Column: 0},
 		{Group: "", Key: "", Line: 22, Column: 1},
 		{Group: "Group", Key: "=value", Line: 26, Column: 0},
@@ -44,6 +45,61 @@ func TestParseUnitFileErrors(t *testing.T) {
 	}
 }
 
+func TestParseUnitFileReturnsPartialUnitOnErrors(t *testing.T) {
+	t.Parallel()
+
+	file, errors := ParseUnitFile("testdata/err.container")
+	require.NotNil(t, file)
+	assert.NotEmpty(t, errors)
+
+	assert.Equal(t, []string{"Container", "Service", "Install", "Group"}, file.ListGroups())
+	assert.False(t, file.HasKey(container.ContainerName))
+	assert.True(t, file.HasKey(container.Image))
+	assert.True(t, file.HasKey(Field{Group: "Service", Key: "Restart", LookupFunc: lookup.LookupLast}))
+	// Keys following an invalid group are not added to the previous group
+	assert.False(t, file.HasKey(Field{Group: "Install", Key: "Test", LookupFunc: lookup.LookupLast}))
+	assert.Empty(t, file.ListKeys("Group"))
+}
+
+func TestParseUnitFileWithCommentsInLineContinuations(t *testing.T) {
+	t.Parallel()
+
+	content := `[Container]
+PodmanArgs=--arg1 \
+# --commented-out \
+  --arg2 \
+  ; --other-comment
+  --arg3
+Image=test
+Exec=a \
+# comment
+
+Label=a=b`
+	file, errors := ParseUnitFileString("test.container", content)
+	require.Empty(t, errors)
+
+	result, ok := file.Lookup(container.PodmanArgs)
+	require.True(t, ok)
+	podmanArgs := container.PodmanArgs.Key
+	assert.Equal(t, []UnitValue{
+		onLine(UnitValue{Key: podmanArgs, Value: "--arg1", Line: 2, Column: 11}),
+		at(UnitValue{Key: podmanArgs, Value: "--arg2", Line: 4, Column: 2}, len("--arg2"), 2, podmanArgs),
+		at(UnitValue{Key: podmanArgs, Value: "--arg3", Line: 6, Column: 2}, len("--arg3"), 2, podmanArgs),
+	}, result.Values())
+
+	result, ok = file.Lookup(container.Image)
+	require.True(t, ok)
+	assert.Equal(t, []UnitValue{onLine(UnitValue{Key: container.Image.Key, Value: "test", Line: 7, Column: 6})},
+		result.Values())
+
+	// A blank line ends the line continuation
+	result, ok = file.Lookup(container.Exec)
+	require.True(t, ok)
+	assert.Equal(t, []UnitValue{onLine(UnitValue{Key: container.Exec.Key, Value: "a", Line: 8, Column: 5})},
+		result.Values())
+	assert.True(t, file.HasKey(container.Label))
+}
+
 func TestParseUnitFile(t *testing.T) {
 	t.Parallel()
 
@@ -51,6 +107,45 @@ func TestParseUnitFile(t *testing.T) {
 	assertUnitFileParsedCorrectly(t, file, errors)
 }
 
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	bytes, err := os.ReadFile("testdata/unit.container")
+	require.NoError(t, err)
+	file, errors := Parse(strings.NewReader(string(bytes)), "dir/unit.container")
+	assert.Equal(t, "unit.container", file.FileName())
+	assertUnitFileParsedCorrectly(t, file, errors)
+}
+
+func TestParseFS(t *testing.T) {
+	t.Parallel()
+
+	bytes, err := os.ReadFile("testdata/unit.container")
+	require.NoError(t, err)
+	fsys := fstest.MapFS{"units/unit.container": {Data: bytes}}
+
+	file, errors := ParseFS(fsys, "units/unit.container")
+	assert.Equal(t, "unit.container", file.FileName())
+	assert.Equal(t, UnitTypeContainer, file.UnitType())
+	assertUnitFileParsedCorrectly(t, file, errors)
+
+	file, errors = ParseFS(fsys, "units/missing.container")
+	assert.Nil(t, file)
+	require.Len(t, errors, 1)
+	require.ErrorIs(t, &errors[0], fs.ErrNotExist)
+}
+
+func TestParseNotUnitFile(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"Makefile", "dir/unit.txt", "dir/.container.d"} {
+		file, errors := Parse(strings.NewReader("[Container]\nImage=test"), name)
+		assert.Nil(t, file)
+		require.Len(t, errors, 1)
+		assert.Contains(t, errors[0].Error(), "is not a unit file")
+	}
+}
+
 func TestParseUnitFileString(t *testing.T) {
 	t.Parallel()
 
@@ -64,31 +159,47 @@ func TestParseUnitFileString(t *testing.T) {
 
 var expectedGroups = map[string]map[string][]UnitValue{
 	"Container": {
-		container.Image.Key:       {UnitValue{Key: container.Image.Key, Value: "my-image", Line: 3, Column: 6}},
-		container.PublishPort.Key: {UnitValue{Key: container.PublishPort.Key, Value: "8080:8080/tcp", Line: 4, Column: 12}},
-		container.Network.Key:     {UnitValue{Key: container.Network.Key, Value: "my-network", Line: 6, Column: 15}},
+		container.Image.Key:       {onLine(UnitValue{Key: container.Image.Key, Value: "my-image", Line: 3, Column: 6})},
+		container.PublishPort.Key: {onLine(UnitValue{Key: container.PublishPort.Key, Value: "8080:8080/tcp", Line: 4, Column: 12})},
+		container.Network.Key:     {onLine(UnitValue{Key: container.Network.Key, Value: "my-network", Line: 6, Column: 15})},
 		container.Environment.Key: {
-			UnitValue{Key: "env1", Value: "value1", Line: 8, Column: 12},
-			UnitValue{Key: "env2", Value: "value2", Line: 8, Column: 12}},
-		container.ReadOnly.Key: {UnitValue{Key: container.ReadOnly.Key, Value: "true", Line: 12, Column: 9}},
+			at(UnitValue{Key: "env1", Value: "value1", Line: 8, Column: 12}, len("env1=value1"), 8, "Environment"),
+			at(UnitValue{Key: "env2", Value: "value2", Line: 9, Column: 4}, len("env2=value2"), 8, "Environment")},
+		container.ReadOnly.Key: {onLine(UnitValue{Key: container.ReadOnly.Key, Value: "true", Line: 12, Column: 9})},
 		container.EnvironmentFile.Key: {
-			UnitValue{Key: container.EnvironmentFile.Key, Value: "env1", Line: 14, Column: 16},
-			UnitValue{Key: container.EnvironmentFile.Key, Value: "env2", Line: 14, Column: 21},
-			UnitValue{Key: container.EnvironmentFile.Key, Value: "env3", Line: 15, Column: 16},
+			onLine(UnitValue{Key: container.EnvironmentFile.Key, Value: "env1", Line: 14, Column: 16}),
+			onLine(UnitValue{Key: container.EnvironmentFile.Key, Value: "env2", Line: 14, Column: 21}),
+			onLine(UnitValue{Key: container.EnvironmentFile.Key, Value: "env3", Line: 15, Column: 16}),
 		},
-		container.Exec.Key: {UnitValue{Key: container.Exec.Key, Value: "value", Line: 19, Column: 5}},
+		container.Exec.Key: {onLine(UnitValue{Key: container.Exec.Key, Value: "value", Line: 19, Column: 5})},
 	},
 	"Service": {
-		"Restart":         {UnitValue{Key: "Restart", Value: "always", Line: 23, Column: 8}},
-		"TimeoutStartSec": {UnitValue{Key: "TimeoutStartSec", Value: "900", Line: 25, Column: 16}},
+		"Restart":         {onLine(UnitValue{Key: "Restart", Value: "always", Line: 23, Column: 8})},
+		"TimeoutStartSec": {onLine(UnitValue{Key: "TimeoutStartSec", Value: "900", Line: 25, Column: 16})},
 	},
 	"Install": {
 		"WantedBy": {
-			UnitValue{Key: "WantedBy", Value: "multi-user.target", Line: 29, Column: 9},
-			UnitValue{Key: "WantedBy", Value: "default.target", Line: 29, Column: 27}},
+			onLine(UnitValue{Key: "WantedBy", Value: "multi-user.target", Line: 29, Column: 9}),
+			onLine(UnitValue{Key: "WantedBy", Value: "default.target", Line: 29, Column: 27})},
 	},
 }
 
+// at sets the ranges of an expected value spanning rawLength bytes in the file whose assignment starts with key at
+// the beginning of keyLine
+func at(value UnitValue, rawLength, keyLine int, key string) UnitValue {
+	value.Range = Range{
+		Start: Position{Line: value.Line, Column: value.Column},
+		End:   Position{Line: value.Line, Column: value.Column + rawLength},
+	}
+	value.KeyRange = Range{Start: Position{Line: keyLine, Column: 0}, End: Position{Line: keyLine, Column: len(key)}}
+	return value
+}
+
+// onLine sets the ranges of an expected value written as is on the line of its key
+func onLine(value UnitValue) UnitValue {
+	return at(value, len(value.Value), value.Line, value.Key)
+}
+
 var additionalFields = map[string]map[string]Field{
 	"Service": {
 		"Restart":         Field{Group: "Service", Key: "Restart", LookupFunc: lookup.Lookup},
@@ -4,6 +4,8 @@ import (
 	"errors"
 	"strings"
 	"unicode"
+
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
 )
 
 /* Functions to split/join, unescape/escape strings similar to Exec=... lines in unit files */
@@ -233,12 +235,29 @@ var (
 	errUnbalancedEscape      = errors.New("unbalanced escape")
 )
 
+// wordError locates an error of extractFirstWord. offset is the position of the bad quote or escape sequence in the
+// input and length is its length.
+type wordError struct {
+	err    error
+	offset int
+	length int
+}
+
+func (e *wordError) Error() string {
+	return e.err.Error()
+}
+
+func (e *wordError) Unwrap() error {
+	return e.err
+}
+
 // Returns: word, remaining, more-words, error
 //
 //nolint:funlen
 func extractFirstWord(in string, separators string, flags SplitFlags) (string, string, bool, error) {
 	var s strings.Builder
 	var quote byte     // 0 or ' or "
+	quoteStart := 0    // position of the opening quote
 	backslash := false // whether we've just seen a backslash
 
 	// The string handling in this function is a bit weird, using
@@ -306,7 +325,7 @@ loop1:
 				if flags&SplitRelax != 0 {
 					goto finishForceTerminate
 				}
-				return "", "", false, errUnbalancedEscape
+				return "", "", false, &wordError{err: errUnbalancedEscape, offset: p - 1, length: 1}
 			}
 
 			if flags&(SplitCUnescape|SplitUnescapeSeparators) != 0 {
@@ -329,7 +348,7 @@ loop1:
 					s.WriteByte('\\')
 					s.WriteByte(c)
 				default:
-					return "", "", false, errUnsupportedEscapeChar
+					return "", "", false, &wordError{err: errUnsupportedEscapeChar, offset: p - 1, length: 2}
 				}
 			} else {
 				s.WriteByte(c)
@@ -345,7 +364,7 @@ loop1:
 					if flags&SplitRelax != 0 {
 						goto finishForceTerminate
 					}
-					return "", "", false, errUnbalancedQuotes
+					return "", "", false, &wordError{err: errUnbalancedQuotes, offset: quoteStart, length: 1}
 				case c == quote:
 					/* found the end quote */
 					quote = 0
@@ -371,6 +390,7 @@ loop1:
 					goto finishForceTerminate
 				case (c == '\'' || c == '"') && (flags&(SplitKeepQuote|SplitUnquote) != 0):
 					quote = c
+					quoteStart = p
 					if flags&SplitUnquote != 0 {
 						break nonquoteloop
 					}
@@ -420,33 +440,46 @@ finishForceNext:
 	return s.String(), in[p:], true, nil
 }
 
+// splitValueAppend splits a value into words and appends them to appendTo. Nothing is appended if the value cannot
+// be split and the returned error is a model.LookupError locating the bad quote or escape sequence.
 func splitValueAppend(appendTo []unitValue, u unitValue, separators string, flags SplitFlags) ([]unitValue, error) {
 	orig := appendTo
 	s := u.value
-	column := u.valueColumn
+	offset := 0
 	for {
 		word, remaining, moreWords, err := extractFirstWord(s, separators, flags|SplitRetainSeparators)
 		if err != nil {
+			var wordErr *wordError
+			if errors.As(err, &wordErr) {
+				start := offset + wordErr.offset
+				err = M.LookupError{Err: wordErr.err, Range: M.Range{
+					Start: u.positionOf(start),
+					End:   u.positionOf(start + wordErr.length),
+					File:  u.file,
+				}}
+			}
 			return orig, err
 		}
 
 		if !moreWords {
 			break
 		}
+
+		// The word ends where the remaining string starts. It can be longer than the word because of quotes
+		// and escapes.
+		start := offset + len(s) - len(strings.TrimLeft(s, separators))
+		offset += len(s) - len(remaining)
+		startPosition := u.positionOf(start)
 		appendTo = append(appendTo, unitValue{
 			key:         u.key,
 			value:       word,
-			line:        u.line,
-			valueColumn: column,
+			line:        startPosition.Line,
+			valueColumn: startPosition.Column,
+			file:        u.file,
+			end:         u.positionOf(offset),
+			keyRange:    u.keyRange,
 		})
 		s = remaining
-		for _, char := range remaining {
-			if !strings.ContainsRune(WhitespaceSeparators, char) {
-				break
-			}
-			column++
-		}
-		column += len(word)
 	}
 	return appendTo, nil
 }
@@ -3,6 +3,7 @@ package parser
 import (
 	"testing"
 
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -23,9 +24,9 @@ func TestSplitValueAppend(t *testing.T) {
 	}
 
 	expectedValues := []unitValue{
-		{key: "TestKey", value: "TestValue", line: 10, valueColumn: 5},
-		{key: "TestKey", value: "TestValue2", line: 10, valueColumn: 19},
-		{key: "TestKey", value: "TestValue3", line: 10, valueColumn: 33},
+		{key: "TestKey", value: "TestValue", line: 10, valueColumn: 5, end: M.Position{Line: 10, Column: 14}},
+		{key: "TestKey", value: "TestValue2", line: 10, valueColumn: 19, end: M.Position{Line: 10, Column: 29}},
+		{key: "TestKey", value: "TestValue3", line: 10, valueColumn: 33, end: M.Position{Line: 10, Column: 43}},
 	}
 
 	assert.Equal(t, expectedValues, values)
@@ -209,7 +210,7 @@ func TestExtractFirstWord(t *testing.T) {
 			}
 			output, err := runExtractFirstWord(test.input, separators, test.flags)
 			if err != nil {
-				require.ErrorIs(t, test.expectedError, err)
+				require.ErrorIs(t, err, test.expectedError)
 			}
 
 			require.Lenf(t, output, len(test.expected), "Expected: %v, Got: %v", test.expected, output)
@@ -0,0 +1,376 @@
+package parser
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode"
+)
+
+// NodeKind is the kind of logical line held by a Node
+type NodeKind int
+
+const (
+	BlankNode NodeKind = iota
+	CommentNode
+	GroupNode
+	KeyValueNode
+	InvalidNode
+)
+
+func (k NodeKind) String() string {
+	switch k {
+	case BlankNode:
+		return "blank"
+	case CommentNode:
+		return "comment"
+	case GroupNode:
+		return "group"
+	case KeyValueNode:
+		return "key-value"
+	case InvalidNode:
+		return "invalid"
+	default:
+		return fmt.Sprintf("NodeKind(%d)", int(k))
+	}
+}
+
+// Node is a logical line of a unit file. A key-value pair with line continuations spans several physical lines.
+// Raw holds the exact bytes of the node without the line terminator of its last line which is held by Newline.
+type Node struct {
+	Kind    NodeKind
+	Raw     string
+	Newline string
+
+	// Line is the first physical line of the node starting at 1 and Offset is its position in bytes in the file
+	Line   int
+	Offset int
+}
+
+// nodeLine is a trimmed physical line of a node. indent is the number of bytes trimmed at its start.
+type nodeLine struct {
+	number int
+	indent int
+	text   string
+}
+
+// lines returns the trimmed physical lines of the node as seen by systemd. Like systemd's config_parse, comment
+// lines inside a line continuation are skipped.
+func (n *Node) lines() []nodeLine {
+	physicalLines := strings.Split(n.Raw, "\n")
+	lines := make([]nodeLine, 0, len(physicalLines))
+	for i, physicalLine := range physicalLines {
+		line := strings.TrimSpace(physicalLine)
+		if i > 0 && len(line) > 0 && lineIsComment(line) {
+			continue
+		}
+		indent := len(physicalLine) - len(strings.TrimLeftFunc(physicalLine, unicode.IsSpace))
+		lines = append(lines, nodeLine{number: n.Line + i, indent: indent, text: line})
+	}
+	return lines
+}
+
+// Text returns the content of the node as seen by systemd: every physical line is trimmed, comment lines inside
+// line continuations are skipped and the remaining lines are joined by '\n'
+func (n *Node) Text() string {
+	lines := n.lines()
+	texts := make([]string, len(lines))
+	for i, line := range lines {
+		texts[i] = line.text
+	}
+	return strings.Join(texts, "\n")
+}
+
+// EndLine returns the last physical line of the node
+func (n *Node) EndLine() int {
+	return n.Line + strings.Count(n.Raw, "\n")
+}
+
+// GroupName returns the name of a GroupNode
+func (n *Node) GroupName() string {
+	if n.Kind != GroupNode {
+		return ""
+	}
+
+	text := n.Text()
+	return text[1:strings.Index(text, "]")]
+}
+
+// Key returns the key of a KeyValueNode
+func (n *Node) Key() string {
+	if n.Kind != KeyValueNode {
+		return ""
+	}
+
+	return strings.TrimSpace(n.Raw[:strings.IndexByte(n.Raw, '=')])
+}
+
+// Value returns the raw value of a KeyValueNode. Line continuations are kept as is.
+func (n *Node) Value() string {
+	if n.Kind != KeyValueNode {
+		return ""
+	}
+
+	return n.Raw[n.valueStart():]
+}
+
+func (n *Node) valueStart() int {
+	start := strings.IndexByte(n.Raw, '=') + 1
+	for start < len(n.Raw) && (n.Raw[start] == ' ' || n.Raw[start] == '\t') {
+		start++
+	}
+	return start
+}
+
+// SyntaxTree is a lossless representation of a unit file. Writing it back reproduces the parsed content exactly
+// and its mutation helpers only touch the lines they change.
+type SyntaxTree struct {
+	Nodes []*Node
+}
+
+var (
+	ErrGroupNotFound    = errors.New("group not found")
+	ErrGroupExists      = errors.New("group already exists")
+	ErrInvalidGroupName = errors.New("invalid group name")
+	ErrInvalidKeyName   = errors.New("invalid key name")
+	ErrInvalidValue     = errors.New("value cannot contain line breaks or end with a backslash")
+)
+
+// ParseSyntaxTree splits the content of a unit file in logical lines. It never fails: lines that are neither
+// comments, groups nor key-value pairs are kept as InvalidNode.
+func ParseSyntaxTree(content string) *SyntaxTree {
+	tree := &SyntaxTree{Nodes: make([]*Node, 0)}
+	for len(content) > 0 {
+		var raw, newline string
+		raw, newline, content = cutLine(content)
+
+		node := &Node{Kind: nodeKindOf(strings.TrimSpace(raw)), Raw: raw, Newline: newline}
+		if node.Kind == KeyValueNode {
+			// Handle multi-line continuations. Comment lines do not end a continuation.
+			for len(content) > 0 && strings.HasSuffix(node.Text(), "\\") {
+				raw, newline, content = cutLine(content)
+				node.Raw += node.Newline + raw
+				node.Newline = newline
+			}
+		}
+
+		tree.Nodes = append(tree.Nodes, node)
+	}
+
+	tree.reindex()
+	return tree
+}
+
+// cutLine returns the first line of data without its terminator, the terminator and the rest of data
+func cutLine(data string) (string, string, string) {
+	i := strings.IndexByte(data, '\n')
+	if i == -1 {
+		return data, "", ""
+	}
+
+	line, newline := data[:i], "\n"
+	if strings.HasSuffix(line, "\r") {
+		line, newline = line[:len(line)-1], "\r\n"
+	}
+
+	return line, newline, data[i+1:]
+}
+
+func nodeKindOf(line string) NodeKind {
+	switch {
+	case len(line) == 0:
+		return BlankNode
+	case lineIsComment(line):
+		return CommentNode
+	case lineIsGroup(line):
+		return GroupNode
+	case lineIsKeyValuePair(line):
+		return KeyValueNode
+	default:
+		return InvalidNode
+	}
+}
+
+// reindex computes the positions of the nodes after a mutation
+func (t *SyntaxTree) reindex() {
+	line, offset := 1, 0
+	for _, node := range t.Nodes {
+		node.Line, node.Offset = line, offset
+		line = node.EndLine() + 1
+		offset += len(node.Raw) + len(node.Newline)
+	}
+}
+
+// WriteTo writes the unit file represented by the tree
+func (t *SyntaxTree) WriteTo(w io.Writer) (int64, error) {
+	var written int64
+	for _, node := range t.Nodes {
+		n, err := io.WriteString(w, node.Raw+node.Newline)
+		written += int64(n)
+		if err != nil {
+			return written, err
+		}
+	}
+	return written, nil
+}
+
+func (t *SyntaxTree) String() string {
+	var builder strings.Builder
+	_, _ = t.WriteTo(&builder)
+	return builder.String()
+}
+
+// SetKey changes the value of the last occurrence of a key in a group while keeping its key and the spacing
+// around '='. The key is inserted with InsertKey if it is not found.
+func (t *SyntaxTree) SetKey(group, key, value string) error {
+	if err := checkKeyValue(key, value); err != nil {
+		return err
+	}
+
+	indexes := t.keyIndexes(group, key)
+	if len(indexes) == 0 {
+		return t.InsertKey(group, key, value)
+	}
+
+	node := t.Nodes[indexes[len(indexes)-1]]
+	node.Raw = node.Raw[:node.valueStart()] + value
+	t.reindex()
+
+	return nil
+}
+
+// InsertKey adds a new occurrence of a key after the last key-value pair of the last occurrence of a group
+func (t *SyntaxTree) InsertKey(group, key, value string) error {
+	if err := checkKeyValue(key, value); err != nil {
+		return err
+	}
+
+	index := t.insertionIndex(group)
+	if index == -1 {
+		return fmt.Errorf("%w: %s", ErrGroupNotFound, group)
+	}
+
+	previous := t.Nodes[index]
+	node := &Node{Kind: KeyValueNode, Raw: key + "=" + value, Newline: previous.Newline}
+	if previous.Kind == KeyValueNode {
+		node.Raw = previous.Raw[:len(previous.Raw)-len(strings.TrimLeft(previous.Raw, " \t"))] + node.Raw
+	}
+	if previous.Newline == "" {
+		// Keep the file without a line terminator at its end
+		previous.Newline = t.newline()
+	}
+
+	t.Nodes = append(t.Nodes[:index+1], append([]*Node{node}, t.Nodes[index+1:]...)...)
+	t.reindex()
+
+	return nil
+}
+
+// RemoveKey removes every occurrence of a key in a group and returns the number of removed occurrences
+func (t *SyntaxTree) RemoveKey(group, key string) int {
+	indexes := t.keyIndexes(group, key)
+	for i := len(indexes) - 1; i >= 0; i-- {
+		index := indexes[i]
+		if index == len(t.Nodes)-1 && index > 0 && t.Nodes[index].Newline == "" {
+			t.Nodes[index-1].Newline = ""
+		}
+		t.Nodes = append(t.Nodes[:index], t.Nodes[index+1:]...)
+	}
+
+	if len(indexes) > 0 {
+		t.reindex()
+	}
+	return len(indexes)
+}
+
+// AddGroup appends a group at the end of the file separated from the previous lines by a blank line
+func (t *SyntaxTree) AddGroup(group string) error {
+	if valid, _ := groupNameIsValid(group); !valid || nodeKindOf("["+group+"]") != GroupNode {
+		return fmt.Errorf("%w: %s", ErrInvalidGroupName, group)
+	}
+
+	if t.insertionIndex(group) != -1 {
+		return fmt.Errorf("%w: %s", ErrGroupExists, group)
+	}
+
+	newline := t.newline()
+	if len(t.Nodes) > 0 {
+		last := t.Nodes[len(t.Nodes)-1]
+		if last.Newline == "" {
+			last.Newline = newline
+		}
+
+		if last.Kind != BlankNode {
+			t.Nodes = append(t.Nodes, &Node{Kind: BlankNode, Newline: newline})
+		}
+	}
+
+	t.Nodes = append(t.Nodes, &Node{Kind: GroupNode, Raw: "[" + group + "]", Newline: newline})
+	t.reindex()
+
+	return nil
+}
+
+// keyIndexes returns the indexes of the key-value nodes of a key in every occurrence of a group
+func (t *SyntaxTree) keyIndexes(group, key string) []int {
+	indexes := make([]int, 0)
+	currentGroup := ""
+	for i, node := range t.Nodes {
+		switch node.Kind {
+		case GroupNode:
+			currentGroup = node.GroupName()
+		case KeyValueNode:
+			if currentGroup == group && node.Key() == key {
+				indexes = append(indexes, i)
+			}
+		default:
+		}
+	}
+	return indexes
+}
+
+// insertionIndex returns the index of the node after which keys of a group are inserted: the last key-value node
+// of the last occurrence of the group or its header if it is empty. It returns -1 if the group is not found.
+func (t *SyntaxTree) insertionIndex(group string) int {
+	index := -1
+	currentGroup := ""
+	for i, node := range t.Nodes {
+		switch node.Kind {
+		case GroupNode:
+			currentGroup = node.GroupName()
+			if currentGroup == group {
+				index = i
+			}
+		case KeyValueNode:
+			if currentGroup == group {
+				index = i
+			}
+		default:
+		}
+	}
+	return index
+}
+
+// newline returns the line terminator used by the file
+func (t *SyntaxTree) newline() string {
+	for _, node := range t.Nodes {
+		if node.Newline != "" {
+			return node.Newline
+		}
+	}
+	return "\n"
+}
+
+func checkKeyValue(key, value string) error {
+	if valid, _ := keyNameIsValid(key); !valid || strings.ContainsAny(key, "\r\n") ||
+		nodeKindOf(key+"=") != KeyValueNode {
+		return fmt.Errorf("%w: %s", ErrInvalidKeyName, key)
+	}
+
+	if strings.ContainsAny(value, "\r\n") || strings.HasSuffix(value, "\\") {
+		return fmt.Errorf("%w: %s", ErrInvalidValue, value)
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package parser
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseSyntaxTreeIsLossless(t *testing.T) {
+	t.Parallel()
+
+	unit, err := os.ReadFile("testdata/unit.container")
+	require.NoError(t, err)
+	errUnit, err := os.ReadFile("testdata/err.container")
+	require.NoError(t, err)
+
+	tests := []struct {
+		name    string
+		content string
+	}{
+		{"Empty", ""},
+		{"UnitFile", string(unit)},
+		{"UnitFileWithErrors", string(errUnit)},
+		{"NoFinalNewline", "[Container]\nImage=test"},
+		{"CRLF", "[Container]\r\nImage = test \r\n\r\n# comment\r\n"},
+		{"Indentation", "  [Container]  \n\tImage=test\n   ; comment\n"},
+		{"Continuations", "[Container]\nExec=a \\\n   b \\\r\n\tc\nImage=test\n"},
+		{"ContinuationAtEndOfFile", "[Container]\nExec=a \\"},
+		{"CommentsInContinuations", "[Container]\nExec=a \\\n# b \\\n ; c\n  d\nImage=test\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := ParseSyntaxTree(test.content)
+			assert.Equal(t, test.content, tree.String())
+
+			var builder strings.Builder
+			written, err := tree.WriteTo(&builder)
+			require.NoError(t, err)
+			assert.Equal(t, int64(len(test.content)), written)
+			assert.Equal(t, test.content, builder.String())
+		})
+	}
+}
+
+func TestParseSyntaxTree(t *testing.T) {
+	t.Parallel()
+
+	tree := ParseSyntaxTree("# comment\n[Container]\r\n  Image = test\nExec=a \\\n  b\n\nbad line\n")
+	require.Len(t, tree.Nodes, 6)
+
+	expected := []struct {
+		kind   NodeKind
+		line   int
+		offset int
+	}{
+		{CommentNode, 1, 0},
+		{GroupNode, 2, 10},
+		{KeyValueNode, 3, 23},
+		{KeyValueNode, 4, 38},
+		{BlankNode, 6, 51},
+		{InvalidNode, 7, 52},
+	}
+	for i, node := range tree.Nodes {
+		assert.Equal(t, expected[i].kind, node.Kind, "node %d", i)
+		assert.Equal(t, expected[i].line, node.Line, "node %d", i)
+		assert.Equal(t, expected[i].offset, node.Offset, "node %d", i)
+	}
+
+	assert.Equal(t, "Container", tree.Nodes[1].GroupName())
+	assert.Equal(t, "\r\n", tree.Nodes[1].Newline)
+	assert.Equal(t, "Image", tree.Nodes[2].Key())
+	assert.Equal(t, "test", tree.Nodes[2].Value())
+	assert.Equal(t, "Exec", tree.Nodes[3].Key())
+	assert.Equal(t, "a \\\n  b", tree.Nodes[3].Value())
+	assert.Equal(t, "Exec=a \\\nb", tree.Nodes[3].Text())
+	assert.Equal(t, 5, tree.Nodes[3].EndLine())
+}
+
+func TestParseSyntaxTreeSkipsCommentsInContinuations(t *testing.T) {
+	t.Parallel()
+
+	tree := ParseSyntaxTree("[Container]\nExec=a \\\n# b\n  ; c \\\n  d\nImage=test\n")
+	require.Len(t, tree.Nodes, 3)
+
+	node := tree.Nodes[1]
+	assert.Equal(t, KeyValueNode, node.Kind)
+	assert.Equal(t, "Exec=a \\\nd", node.Text())
+	assert.Equal(t, []nodeLine{{number: 2, text: "Exec=a \\"}, {number: 5, indent: 2, text: "d"}}, node.lines())
+	assert.Equal(t, 5, node.EndLine())
+	assert.Equal(t, 6, tree.Nodes[2].Line)
+}
+
+func TestSyntaxTree_SetKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		group    string
+		key      string
+		value    string
+		expected string
+		err      error
+	}{
+		{"ChangesLastOccurrence", "[Container]\nImage=a\n# keep\nImage = b\n", "Container", "Image", "c",
+			"[Container]\nImage=a\n# keep\nImage = c\n", nil},
+		{"ReplacesContinuation", "[Container]\nExec=a \\\n  b\nImage=i\n", "Container", "Exec", "c",
+			"[Container]\nExec=c\nImage=i\n", nil},
+		{"InsertsWhenAbsent", "[Container]\nImage=a\n\n[Service]\n", "Container", "Exec", "run",
+			"[Container]\nImage=a\nExec=run\n\n[Service]\n", nil},
+		{"MissingGroup", "[Container]\n", "Pod", "PodName", "a", "[Container]\n", ErrGroupNotFound},
+		{"InvalidKey", "[Container]\n", "Container", "#Image", "a", "[Container]\n", ErrInvalidKeyName},
+		{"InvalidValue", "[Container]\n", "Container", "Image", "a\nb", "[Container]\n", ErrInvalidValue},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := ParseSyntaxTree(test.content)
+			err := tree.SetKey(test.group, test.key, test.value)
+			require.ErrorIs(t, err, test.err)
+			assert.Equal(t, test.expected, tree.String())
+		})
+	}
+}
+
+func TestSyntaxTree_InsertKey(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"AfterLastKey", "[Container]\nImage=a\n# comment\n\n[Service]\nRestart=always\n",
+			"[Container]\nImage=a\nImage=b\n# comment\n\n[Service]\nRestart=always\n"},
+		{"InLastOccurrenceOfGroup", "[Container]\nImage=a\n[Service]\n[Container]\nExec=e\n",
+			"[Container]\nImage=a\n[Service]\n[Container]\nExec=e\nImage=b\n"},
+		{"AfterEmptyGroupHeader", "[Container]\n\n[Service]\n", "[Container]\nImage=b\n\n[Service]\n"},
+		{"KeepsIndentation", "[Container]\n\tExec=e\n", "[Container]\n\tExec=e\n\tImage=b\n"},
+		{"KeepsCRLF", "[Container]\r\nExec=e\r\n", "[Container]\r\nExec=e\r\nImage=b\r\n"},
+		{"KeepsNoFinalNewline", "[Container]\nExec=e", "[Container]\nExec=e\nImage=b"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := ParseSyntaxTree(test.content)
+			require.NoError(t, tree.InsertKey("Container", "Image", "b"))
+			assert.Equal(t, test.expected, tree.String())
+			assert.Equal(t, test.expected, ParseSyntaxTree(test.expected).String())
+		})
+	}
+}
+
+func TestSyntaxTree_RemoveKey(t *testing.T) {
+	t.Parallel()
+
+	tree := ParseSyntaxTree("[Container]\nImage=a\n# comment\nExec=a \\\n  b\n[Service]\nExec=c\n[Container]\nExec=d")
+	assert.Equal(t, 2, tree.RemoveKey("Container", "Exec"))
+	assert.Equal(t, "[Container]\nImage=a\n# comment\n[Service]\nExec=c\n[Container]", tree.String())
+	assert.Equal(t, 6, tree.Nodes[len(tree.Nodes)-1].Line)
+
+	assert.Equal(t, 0, tree.RemoveKey("Container", "Missing"))
+}
+
+func TestSyntaxTree_AddGroup(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{"EmptyFile", "", "[Install]\n"},
+		{"SeparatedByBlankLine", "[Container]\nImage=a\n", "[Container]\nImage=a\n\n[Install]\n"},
+		{"AfterBlankLine", "[Container]\nImage=a\n\n", "[Container]\nImage=a\n\n[Install]\n"},
+		{"NoFinalNewline", "[Container]\r\nImage=a", "[Container]\r\nImage=a\r\n\r\n[Install]\r\n"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			tree := ParseSyntaxTree(test.content)
+			require.NoError(t, tree.AddGroup("Install"))
+			assert.Equal(t, test.expected, tree.String())
+			require.NoError(t, tree.InsertKey("Install", "WantedBy", "default.target"))
+		})
+	}
+
+	tree := ParseSyntaxTree("[Install]\n")
+	require.ErrorIs(t, tree.AddGroup("Install"), ErrGroupExists)
+	require.ErrorIs(t, tree.AddGroup("In]stall"), ErrInvalidGroupName)
+	assert.Equal(t, "[Install]\n", tree.String())
+}
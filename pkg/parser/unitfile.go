@@ -25,8 +25,9 @@ func newUnitFile(filename string, unitType M.UnitType) unitFile {
 }
 
 type unitGroup struct {
-	name  string
-	lines []unitLine
+	name   string
+	header M.Range
+	lines  []unitLine
 }
 
 func (g *unitGroup) String() string {
@@ -83,18 +84,67 @@ type unitValue struct {
 	key         string
 	line        int
 	valueColumn int
+	// file is the path of the drop-in holding the value. It is empty for values of the unit file itself.
+	file string
+	// end is the position after the last character of the value
+	end M.Position
+	// segments locate the continuation lines of a value spanning several lines
+	segments []valueSegment
+	keyRange M.Range
 
 	value     string
 	intValue  int
 	boolValue bool
 }
 
+// valueSegment is a continuation line of a value. offset is the position in the value where the line starts.
+type valueSegment struct {
+	offset int
+	line   int
+	column int
+}
+
+// positionOf returns the position of the character at the offset in the value
+func (v unitValue) positionOf(offset int) M.Position {
+	position := M.Position{Line: v.line, Column: v.valueColumn + offset}
+	for _, segment := range v.segments {
+		if offset >= segment.offset {
+			position = M.Position{Line: segment.line, Column: segment.column + offset - segment.offset}
+		}
+	}
+	return position
+}
+
+// applyLineContinuation removes the line continuations from the value and moves its segments accordingly
+func (v unitValue) applyLineContinuation() unitValue {
+	if !strings.Contains(v.value, "\\\n") {
+		return v
+	}
+
+	// Every segment starts after a line continuation. The ones before it and its own are removed.
+	segments := make([]valueSegment, len(v.segments))
+	for i, segment := range v.segments {
+		segment.offset -= (i + 1) * len("\\\n")
+		segments[i] = segment
+	}
+
+	v.value = strings.ReplaceAll(v.value, "\\\n", "")
+	v.segments = segments
+	return v
+}
+
 func (v unitValue) toModel() M.UnitValue {
 	return M.UnitValue{
 		Key:    v.key,
 		Value:  v.value,
 		Line:   v.line,
 		Column: v.valueColumn,
+		Range: M.Range{
+			Start: M.Position{Line: v.line, Column: v.valueColumn},
+			End:   v.end,
+			File:  v.file,
+		},
+		KeyRange: v.keyRange,
 	}
 }
 
@@ -108,8 +158,8 @@ func (f unitFile) UnitType() M.UnitType {
 
 func (f unitFile) Lookup(field M.Field) (M.LookupResult, bool) {
 	if fn, ok := lookupFuncs[field.LookupFunc]; ok {
-		values := fn(f, field)
-		return &lookupResult{values: values}, len(values) > 0
+		values, errs := fn(f, field)
+		return &lookupResult{values: values, errors: errs}, len(values) > 0
 	}
 
 	panic(fmt.Sprintf("lookup mode %s is not supported for field %s", field.LookupFunc.Name, field.Key))
@@ -120,6 +170,15 @@ func (f unitFile) HasGroup(groupName string) bool {
 	return ok
 }
 
+func (f unitFile) GroupRange(groupName string) (M.Range, bool) {
+	g, ok := f.groupByName[groupName]
+	if !ok {
+		return M.Range{}, false
+	}
+
+	return g.header, true
+}
+
 func (f unitFile) ListGroups() []string {
 	groups := make([]string, len(f.groups))
 	for i, group := range f.groups {
@@ -138,8 +197,8 @@ func (f unitFile) ListKeys(groupName string) []M.UnitKey {
 	keys := make([]M.UnitKey, 0, len(g.lines))
 	for _, line := range g.lines {
 		if _, ok := hash[line.key]; !ok {
-			line.value.line -= strings.Count(line.value.value, "\n")
-			keys = append(keys, M.UnitKey{Key: line.key, Line: line.value.line})
+			keyRange := line.value.keyRange
+			keys = append(keys, M.UnitKey{Key: line.key, Line: keyRange.Start.Line, Range: keyRange})
 			hash[line.key] = struct{}{}
 		}
 	}
@@ -147,6 +206,19 @@ func (f unitFile) ListKeys(groupName string) []M.UnitKey {
 	return keys
 }
 
+func (f unitFile) ListAssignments(groupName string) []M.UnitValue {
+	g, ok := f.groupByName[groupName]
+	if !ok {
+		return make([]M.UnitValue, 0)
+	}
+
+	values := make([]M.UnitValue, 0, len(g.lines))
+	for _, line := range g.lines {
+		values = append(values, line.value.applyLineContinuation().toModel())
+	}
+	return values
+}
+
 func (f unitFile) HasValue(field M.Field) bool {
 	value, found := f.lookupBase(field)
 	return found && len(value.value) > 0
@@ -42,9 +42,50 @@ func TestUnitFile_ListKeys(t *testing.T) {
 		{Key: "EnvironmentFile", Line: 14},
 		{Key: "Exec", Line: 18},
 	}
+	for i, key := range expectedKeys {
+		expectedKeys[i].Range = model.Range{
+			Start: model.Position{Line: key.Line, Column: 0},
+			End:   model.Position{Line: key.Line, Column: len(key.Key)},
+		}
+	}
 	assert.ElementsMatch(t, expectedKeys, unit.ListKeys("Container"))
 }
 
+func TestUnitFile_GroupRange(t *testing.T) {
+	t.Parallel()
+
+	unit, errors := ParseUnitFileString("test.container", "# comment\n  [Container] \nImage=test\n[Container]")
+	require.Empty(t, errors)
+
+	groupRange, ok := unit.GroupRange("Container")
+	assert.True(t, ok)
+	assert.Equal(t, model.Range{
+		Start: model.Position{Line: 2, Column: 2},
+		End:   model.Position{Line: 2, Column: 13},
+	}, groupRange)
+
+	_, ok = unit.GroupRange("Pod")
+	assert.False(t, ok)
+}
+
+func TestUnitFile_ValueRanges(t *testing.T) {
+	t.Parallel()
+
+	unit, errors := ParseUnitFileString("test.container", "[Container]\n  Exec = \"a b\" \\\n    c")
+	require.Empty(t, errors)
+
+	result, ok := unit.Lookup(container.Exec)
+	require.True(t, ok)
+
+	keyRange := model.Range{Start: model.Position{Line: 2, Column: 2}, End: model.Position{Line: 2, Column: 6}}
+	assert.Equal(t, []model.UnitValue{
+		{Key: "Exec", Value: "a b", Line: 2, Column: 9, KeyRange: keyRange, Range: model.Range{
+			Start: model.Position{Line: 2, Column: 9}, End: model.Position{Line: 2, Column: 14}}},
+		{Key: "Exec", Value: "c", Line: 3, Column: 4, KeyRange: keyRange, Range: model.Range{
+			Start: model.Position{Line: 3, Column: 4}, End: model.Position{Line: 3, Column: 5}}},
+	}, result.Values())
+}
+
 func TestUnitFile_HasValue(t *testing.T) {
 	t.Parallel()
 
@@ -77,6 +77,10 @@ func (t testUnitFile) HasGroup(groupName string) bool {
 	panic("implement me")
 }
 
+func (t testUnitFile) GroupRange(groupName string) (M.Range, bool) {
+	panic("implement me")
+}
+
 func (t testUnitFile) ListGroups() []string {
 	panic("implement me")
 }
@@ -85,6 +89,10 @@ func (t testUnitFile) ListKeys(groupName string) []M.UnitKey {
 	panic("implement me")
 }
 
+func (t testUnitFile) ListAssignments(groupName string) []M.UnitValue {
+	panic("implement me")
+}
+
 func (t testUnitFile) HasKey(field M.Field) bool {
 	panic("implement me")
 }
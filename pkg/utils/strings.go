@@ -0,0 +1,25 @@
+package utils
+
+// EditDistance returns the Levenshtein distance between a and b which is the number of characters that must be
+// inserted, deleted or substituted to turn a into b
+func EditDistance(a, b string) int {
+	runesA, runesB := []rune(a), []rune(b)
+	previous := make([]int, len(runesB)+1)
+	current := make([]int, len(runesB)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(runesA); i++ {
+		current[0] = i
+		for j := 1; j <= len(runesB); j++ {
+			substitution := previous[j-1]
+			if runesA[i-1] != runesB[j-1] {
+				substitution++
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, substitution)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(runesB)]
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEditDistance(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"Image", "Image", 0},
+		{"", "Image", 5},
+		{"Imgae", "Image", 2},
+		{"Imag", "Image", 1},
+		{"kitten", "sitting", 3},
+		{"PodName", "podname", 2},
+	}
+	for _, test := range tests {
+		assert.Equal(t, test.expected, EditDistance(test.a, test.b), "%s -> %s", test.a, test.b)
+		assert.Equal(t, test.expected, EditDistance(test.b, test.a), "%s -> %s", test.b, test.a)
+	}
+}
@@ -0,0 +1,36 @@
+package utils
+
+import (
+	"strconv"
+	"strings"
+)
+
+// CompareVersions compares versions like v4.9.0 and 5.3 part by part. Parts are compared numerically when possible,
+// missing parts count as 0 and the v prefix is optional.
+func CompareVersions(a, b string) int {
+	partsA := strings.Split(strings.TrimPrefix(a, "v"), ".")
+	partsB := strings.Split(strings.TrimPrefix(b, "v"), ".")
+	for i := 0; i < len(partsA) || i < len(partsB); i++ {
+		partA, partB := "0", "0"
+		if i < len(partsA) {
+			partA = partsA[i]
+		}
+		if i < len(partsB) {
+			partB = partsB[i]
+		}
+
+		numberA, errA := strconv.Atoi(partA)
+		numberB, errB := strconv.Atoi(partB)
+		if errA != nil || errB != nil {
+			if c := strings.Compare(partA, partB); c != 0 {
+				return c
+			}
+			continue
+		}
+
+		if numberA != numberB {
+			return numberA - numberB
+		}
+	}
+	return 0
+}
@@ -0,0 +1,35 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCompareVersions(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		a, b     string
+		expected int
+	}{
+		{"v5.3.1", "v5.3.1", 0},
+		{"v5.3.1", "5.3.1", 0},
+		{"v5.0.0", "5", 0},
+		{"4.9", "v5.0.0", -1},
+		{"v5.10.0", "v5.3.1", 1},
+		{"v5.3.1", "v5.3", 1},
+		{"v5.4.0-rc1", "v5.4.0-rc2", -1},
+	}
+	for _, test := range tests {
+		actual := CompareVersions(test.a, test.b)
+		switch {
+		case test.expected < 0:
+			assert.Negative(t, actual, "%s < %s", test.a, test.b)
+		case test.expected > 0:
+			assert.Positive(t, actual, "%s > %s", test.a, test.b)
+		default:
+			assert.Zero(t, actual, "%s = %s", test.a, test.b)
+		}
+	}
+}
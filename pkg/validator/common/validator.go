@@ -2,42 +2,300 @@ package common
 
 import (
 	"fmt"
+	"maps"
+	"slices"
+	"strings"
 
 	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
 	model "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/lookup"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/systemd"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/utils"
 	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/validator/rules"
 )
 
-func Validator() V.Validator {
-	return commonValidator{}
+var (
+	UselessReset      = V.NewErrorCategory("useless-reset", V.LevelWarning)
+	InstanceSpecifier = V.NewErrorCategory("instance-specifier", V.LevelWarning)
+	UnexpandedHome    = V.NewErrorCategory("unexpanded-home", V.LevelWarning)
+)
+
+const ErrMalformedValue = "malformed-value"
+
+// typedKinds are the kinds of the values that Quadlet converts to booleans or numbers
+var typedKinds = map[M.ValueKind]bool{
+	M.KindBool:   true,
+	M.KindInt:    true,
+	M.KindUint32: true,
+}
+
+// splitLookupFuncs are the lookups splitting values into words. They drop the values that cannot be split.
+var splitLookupFuncs = map[lookup.LookupFunc]bool{
+	lookup.LookupAllArgs:   true,
+	lookup.LookupLastArgs:  true,
+	lookup.LookupAllKeyVal: true,
+	lookup.LookupAllStrv:   true,
+}
+
+func Validator(options V.Options) V.Validator {
+	return commonValidator{context: V.Context{Options: options}}
 }
 
-type commonValidator struct{}
+type commonValidator struct {
+	context V.Context
+}
 
 func (v commonValidator) Name() string {
 	return "common"
 }
 
 func (v commonValidator) Context() V.Context {
-	return V.Context{}
+	return v.context
 }
 
-var ignoredSystemdGroups = map[string]bool{"Service": true, "Install": true, "Unit": true}
-
 func (v commonValidator) Validate(unit M.UnitFile) []V.ValidationError {
-	validationErrors := make([]V.ValidationError, 0)
+	validationErrors := v.instanceSpecifiers(unit)
 	for _, group := range unit.ListGroups() {
-		if _, ignored := ignoredSystemdGroups[group]; ignored {
+		// The values of the systemd directives are checked by the systemd validator
+		if directives, ok := systemd.Fields[group]; ok {
+			validationErrors = append(validationErrors, v.unknownDirectives(unit, group, directives)...)
 			continue
 		}
 
-		allowedFields := model.Fields[group]
+		allowedFields := rules.TargetFields(v, group)
 		for _, key := range unit.ListKeys(group) {
 			if _, ok := allowedFields[key.Key]; !ok {
-				validationErrors = append(validationErrors, *V.UnknownKey.Err(v.Name(), group, key.Key, key.Line, 0,
-					fmt.Sprintf("key '%s' is not allowed in group '%s'", key.Key, group)))
+				validationErrors = append(validationErrors, *V.UnknownKey.ErrForRange(v.Name(), "", group, key.Key, key.Range,
+					v.unknownKeyMessage(group, key.Key, allowedFields)))
 			}
 		}
+
+		validationErrors = append(validationErrors, v.unsupportedOnTarget(unit, group, allowedFields, model.Fields[group])...)
+		validationErrors = append(validationErrors, v.deprecatedKeys(unit, group, allowedFields)...)
+		validationErrors = append(validationErrors, v.malformedValues(unit, group, allowedFields)...)
+		validationErrors = append(validationErrors, v.typeErrors(unit, group, allowedFields)...)
+		validationErrors = append(validationErrors, v.uselessResets(unit, group, allowedFields)...)
+		validationErrors = append(validationErrors, v.unexpandedHomes(unit, group, allowedFields)...)
+	}
+	return validationErrors
+}
+
+// unknownKeyMessage tells apart the keys that exist in other versions of Podman from the keys that do not exist at all.
+// The allowed key closest to a key that does not exist is suggested along with the summary of its documentation.
+func (v commonValidator) unknownKeyMessage(group, key string, allowed map[string]M.Field) string {
+	if version := v.context.PodmanVersion; version.Fields != nil {
+		if _, ok := model.Fields[group][key]; ok {
+			return fmt.Sprintf("key '%s' is not supported in group '%s' by Podman %s", key, group, version)
+		}
+	}
+
+	message := fmt.Sprintf("key '%s' is not allowed in group '%s'", key, group)
+	suggestion, ok := closestKey(key, allowed)
+	if !ok {
+		return message
+	}
+
+	if summary := model.DocumentedField(allowed[suggestion]).Summary(); summary != "" {
+		return fmt.Sprintf("%s. Did you mean '%s' (%s)?", message, suggestion, summary)
+	}
+	return fmt.Sprintf("%s. Did you mean '%s'?", message, suggestion)
+}
+
+// unknownDirectives reports the keys of a systemd group that are not directives of the systemd version of the model.
+// The keys starting with X- are ignored by systemd.
+func (v commonValidator) unknownDirectives(unit M.UnitFile, group string,
+	directives map[string]M.Field) []V.ValidationError {
+	validationErrors := make([]V.ValidationError, 0)
+	for _, key := range unit.ListKeys(group) {
+		if _, ok := directives[key.Key]; ok || strings.HasPrefix(key.Key, "X-") {
+			continue
+		}
+
+		message := fmt.Sprintf("key '%s' is not a known directive of systemd %s in group '%s'", key.Key,
+			systemd.Version, group)
+		if suggestion, ok := closestKey(key.Key, directives); ok {
+			message = fmt.Sprintf("%s. Did you mean '%s'?", message, suggestion)
+		}
+		validationErrors = append(validationErrors, *V.UnknownKey.ErrForRange(v.Name(), "", group, key.Key, key.Range,
+			message))
+	}
+	return validationErrors
+}
+
+// maxSuggestionDistance is the maximum number of edits between an unknown key and the key suggested for it
+const maxSuggestionDistance = 2
+
+// closestKey returns the allowed key that differs only by case from key or else the one with the fewest edits from it.
+// Ties are broken alphabetically.
+func closestKey(key string, allowed map[string]M.Field) (string, bool) {
+	closest, closestDistance := "", maxSuggestionDistance+1
+	for _, candidate := range slices.Sorted(maps.Keys(allowed)) {
+		if strings.EqualFold(candidate, key) {
+			return candidate, true
+		}
+		if distance := utils.EditDistance(candidate, key); distance < closestDistance {
+			closest, closestDistance = candidate, distance
+		}
+	}
+	return closest, closest != ""
+}
+
+// unsupportedOnTarget reports the allowed keys that are supported by a newer Podman than the minimum targeted version.
+// The first version supporting a key is taken from fields because the keys of a targeted version do not have it.
+func (v commonValidator) unsupportedOnTarget(unit M.UnitFile, group string,
+	allowed, fields map[string]M.Field) []V.ValidationError {
+	validationErrors := make([]V.ValidationError, 0)
+	minimum := v.context.MinPodmanVersion
+	if minimum == "" {
+		return validationErrors
+	}
+
+	for _, key := range unit.ListKeys(group) {
+		if _, ok := allowed[key.Key]; !ok {
+			continue
+		}
+
+		field := fields[key.Key]
+		if field.Since == "" || utils.CompareVersions(minimum, field.Since) >= 0 {
+			continue
+		}
+
+		validationErrors = append(validationErrors, *V.UnsupportedOnTarget.ErrForRange(v.Name(), "", group, key.Key,
+			key.Range, fmt.Sprintf("key '%s' requires Podman %s or newer but the minimum targeted version is %s",
+				field, field.Since, minimum)))
+	}
+	return validationErrors
+}
+
+// deprecatedKeys runs Deprecated on every key of a group that Quadlet marks as deprecated
+func (v commonValidator) deprecatedKeys(unit M.UnitFile, group string, fields map[string]M.Field) []V.ValidationError {
+	validationErrors := make([]V.ValidationError, 0)
+	for _, key := range unit.ListKeys(group) {
+		if field, ok := fields[key.Key]; ok && field.Deprecated {
+			validationErrors = append(validationErrors, rules.Deprecated(v, unit, field)...)
+		}
+	}
+	return validationErrors
+}
+
+// malformedValues reports the values that cannot be split into words because of an unbalanced quote or an invalid
+// escape sequence. Quadlet silently drops them.
+func (v commonValidator) malformedValues(unit M.UnitFile, group string, fields map[string]M.Field) []V.ValidationError {
+	validationErrors := make([]V.ValidationError, 0)
+	for _, key := range unit.ListKeys(group) {
+		field, ok := fields[key.Key]
+		if !ok || !splitLookupFuncs[field.LookupFunc] {
+			continue
+		}
+
+		res, _ := unit.Lookup(field)
+		for _, err := range res.Errors() {
+			validationErrors = append(validationErrors, *V.InvalidValue.ErrForRange(v.Name(), ErrMalformedValue,
+				field.Group, field.Key, err.Range, fmt.Sprintf("value of key '%s' is ignored: %s", field, err)))
+		}
+	}
+	return validationErrors
+}
+
+// typeErrors runs HasValidType on every boolean and integer key of a group
+func (v commonValidator) typeErrors(unit M.UnitFile, group string, fields map[string]M.Field) []V.ValidationError {
+	validationErrors := make([]V.ValidationError, 0)
+	for _, key := range unit.ListKeys(group) {
+		field, ok := fields[key.Key]
+		if ok && typedKinds[field.Kind] {
+			validationErrors = append(validationErrors, rules.HasValidType(v, unit, field)...)
+		}
+	}
+	return validationErrors
+}
+
+// unexpandedHomes warns about the paths starting with '~' in the keys whose values are paths. Neither Quadlet nor
+// systemd expands '~' so the home directory has to be given with the %h specifier.
+func (v commonValidator) unexpandedHomes(unit M.UnitFile, group string, fields map[string]M.Field) []V.ValidationError {
+	validationErrors := make([]V.ValidationError, 0)
+	for _, key := range unit.ListKeys(group) {
+		field, ok := fields[key.Key]
+		if !ok || !field.SpecifierPath {
+			continue
+		}
+
+		res, _ := unit.Lookup(field)
+		for _, value := range res.Values() {
+			if strings.HasPrefix(value.Value, "~") {
+				validationErrors = append(validationErrors, *UnexpandedHome.ErrForValue(v.Name(), "", field, value,
+					fmt.Sprintf("'~' is not expanded in the path '%s' of key '%s'. Use the %%h specifier instead",
+						value.Value, field)))
+			}
+		}
+	}
+	return validationErrors
+}
+
+// instanceSpecifiers warns about the %i and %I specifiers in every group of units that are neither templates like
+// web@.container nor instances like web@blue.container. systemd expands them to an empty string.
+func (v commonValidator) instanceSpecifiers(unit M.UnitFile) []V.ValidationError {
+	validationErrors := make([]V.ValidationError, 0)
+	if M.ParseUnitName(unit.FileName()).Templated {
+		return validationErrors
+	}
+
+	for _, group := range unit.ListGroups() {
+		for _, assignment := range unit.ListAssignments(group) {
+			if specifier, found := findInstanceSpecifier(assignment.Value); found {
+				validationErrors = append(validationErrors, *InstanceSpecifier.ErrForRange(v.Name(), "", group,
+					assignment.Key, assignment.Range, fmt.Sprintf("specifier '%s' of key '%s' expands to an empty "+
+						"string because %s is not a template unit", specifier, assignment.Key, unit.FileName())))
+			}
+		}
+	}
+	return validationErrors
+}
+
+// findInstanceSpecifier returns the first %i or %I specifier of a value. %% is an escaped percent sign.
+func findInstanceSpecifier(value string) (string, bool) {
+	for i := 0; i < len(value)-1; i++ {
+		if value[i] != '%' {
+			continue
+		}
+
+		switch value[i+1] {
+		case 'i', 'I':
+			return value[i : i+2], true
+		case '%':
+			i++
+		}
+	}
+	return "", false
+}
+
+// uselessResets reports empty assignments like 'Key=' that have no effect. An empty assignment clears the values
+// assigned before it which is only useful for keys accepting multiple values that were already assigned.
+func (v commonValidator) uselessResets(unit M.UnitFile, group string, fields map[string]M.Field) []V.ValidationError {
+	validationErrors := make([]V.ValidationError, 0)
+	assigned := make(map[string]bool)
+	for _, assignment := range unit.ListAssignments(group) {
+		field, ok := fields[assignment.Key]
+		if !ok {
+			continue
+		}
+
+		if len(assignment.Value) > 0 {
+			assigned[field.Key] = true
+			continue
+		}
+
+		switch {
+		case !field.Multiple():
+			validationErrors = append(validationErrors, *UselessReset.ErrForKey(v.Name(), "", field,
+				assignment, fmt.Sprintf("key '%s' accepts a single value so the empty assignment only "+
+					"resets it to its default value. Remove the key instead", field)))
+		case !assigned[field.Key]:
+			validationErrors = append(validationErrors, *UselessReset.ErrForKey(v.Name(), "", field,
+				assignment, fmt.Sprintf("the empty assignment of key '%s' has no effect because "+
+					"no value was assigned before it", field)))
+		}
+		assigned[field.Key] = false
 	}
 	return validationErrors
 }
@@ -3,9 +3,16 @@ package common
 import (
 	"testing"
 
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/container"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/systemd"
+	P "github.com/AhmedMoalla/quadlet-lint/pkg/parser"
 	"github.com/AhmedMoalla/quadlet-lint/pkg/testutils"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/utils"
 	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/validator/rules"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 const unitFileToTest = `[Container]
@@ -17,12 +24,12 @@ Test=bla
 Name=my-pod
 Other=bla
 
-# Ignored group
+# Systemd group
 [Service]
 dazdaz=dadazdazd
 `
 
-var validator = Validator()
+var validator = Validator(V.Options{})
 
 func TestCommonValidator_Validate(t *testing.T) {
 	t.Parallel()
@@ -30,14 +37,327 @@ func TestCommonValidator_Validate(t *testing.T) {
 	unit := testutils.ParseString(t, unitFileToTest)
 
 	errs := validator.Validate(unit)
-	assert.Len(t, errs, 3)
+	assert.Len(t, errs, 4)
 
-	expectedErrLines := []int{4, 7, 8}
+	expectedErrLines := []int{4, 7, 8, 12}
 	for i, err := range errs {
 		assertUnknownKeyError(t, err, expectedErrLines[i])
 	}
 }
 
+func TestCommonValidator_ValidateQuadletGroupInEveryUnitType(t *testing.T) {
+	t.Parallel()
+
+	for _, unitType := range M.AllUnitTypes {
+		t.Run(unitType.Name, func(t *testing.T) {
+			t.Parallel()
+
+			unit, parseErrs := P.ParseUnitFileString("test"+unitType.Ext,
+				"[Quadlet]\nDefaultDependencies=false\nUnknown=true")
+			require.Empty(t, parseErrs)
+
+			errs := validator.Validate(unit)
+			assert.Len(t, errs, 1)
+			assertUnknownKeyError(t, errs[0], 3)
+		})
+	}
+}
+
+func TestCommonValidator_ValidateUselessResets(t *testing.T) {
+	t.Parallel()
+
+	unit := testutils.ParseString(t, `[Container]
+ContainerName=
+DNS=
+DNS=1.1.1.1
+DNS=
+DNS=
+Unknown=`)
+
+	errs := validator.Validate(unit)
+	require.Len(t, errs, 4)
+
+	assertUnknownKeyError(t, errs[0], 7)
+	expectedErrLines := []int{2, 3, 6}
+	for i, err := range errs[1:] {
+		assert.Equal(t, validator.Name(), err.ValidatorName)
+		assert.Equal(t, UselessReset, err.ErrorCategory)
+		assert.Equal(t, expectedErrLines[i], err.Line)
+		assert.Equal(t, 0, err.Column)
+	}
+}
+
+func TestCommonValidator_ValidateMalformedValues(t *testing.T) {
+	t.Parallel()
+
+	unit := testutils.ParseString(t, `[Container]
+Exec=/bin/sh -c "echo \q"
+Environment=A=1 B=\x4g
+AddCapability=CAP_A 'CAP_B
+Mask=/proc`)
+
+	errs := validator.Validate(unit)
+	require.Len(t, errs, 3)
+
+	expectedLocations := []V.Location{
+		{Line: 2, Column: 22, EndLine: 2, EndColumn: 24},
+		{Line: 3, Column: 18, EndLine: 3, EndColumn: 20},
+		{Line: 4, Column: 20, EndLine: 4, EndColumn: 21},
+	}
+	for i, err := range errs {
+		assert.Equal(t, validator.Name(), err.ValidatorName)
+		assert.Equal(t, V.InvalidValue, err.ErrorCategory)
+		assert.Equal(t, ErrMalformedValue, err.ErrorName)
+		assert.Equal(t, expectedLocations[i], err.Location)
+	}
+}
+
+func TestCommonValidator_ValidateTypes(t *testing.T) {
+	t.Parallel()
+
+	unit := testutils.ParseString(t, `[Container]
+ReadOnly=maybe
+Notify=yes
+RemapUidSize=-1
+
+[Quadlet]
+DefaultDependencies=nope`)
+
+	errs := validator.Validate(unit)
+	require.Len(t, errs, 3)
+
+	expectedLocations := []V.Location{
+		{Line: 2, Column: 9, EndLine: 2, EndColumn: 14},
+		{Line: 4, Column: 13, EndLine: 4, EndColumn: 15},
+		{Line: 7, Column: 20, EndLine: 7, EndColumn: 24},
+	}
+	for i, err := range errs {
+		assert.Equal(t, validator.Name(), err.ValidatorName)
+		assert.Equal(t, V.InvalidValue, err.ErrorCategory)
+		assert.Equal(t, rules.ErrBadType, err.ErrorName)
+		assert.Equal(t, expectedLocations[i], err.Location)
+	}
+}
+
+func TestCommonValidator_ValidateInstanceSpecifiers(t *testing.T) {
+	t.Parallel()
+
+	content := `[Container]
+Image=docker.io/library/app
+Exec=run %i
+Environment=ESCAPED=%%i
+
+[Service]
+Environment=INSTANCE=%I`
+
+	unit, parseErrs := P.ParseUnitFileString("app.container", content)
+	require.Empty(t, parseErrs)
+
+	errs := validator.Validate(unit)
+	require.Len(t, errs, 2)
+	assert.Equal(t, InstanceSpecifier, errs[0].ErrorCategory)
+	assert.Equal(t, V.Location{Line: 3, Column: 5, EndLine: 3, EndColumn: 11}, errs[0].Location)
+	assert.Equal(t, InstanceSpecifier, errs[1].ErrorCategory)
+	assert.Equal(t, "Service", errs[1].Group)
+
+	for _, name := range []string{"app@.container", "app@blue.container"} {
+		unit, parseErrs = P.ParseUnitFileString(name, content)
+		require.Empty(t, parseErrs)
+		assert.Empty(t, validator.Validate(unit), name)
+	}
+}
+
+func TestCommonValidator_ValidateUnexpandedHomes(t *testing.T) {
+	t.Parallel()
+
+	unit := testutils.ParseString(t, `[Container]
+Image=docker.io/library/app
+EnvironmentFile=~/app.env
+EnvironmentFile=%h/app.env
+Volume=~/data:/data
+Label=home=~/data`)
+
+	errs := validator.Validate(unit)
+	require.Len(t, errs, 2)
+	assert.Equal(t, UnexpandedHome, errs[0].ErrorCategory)
+	assert.Equal(t, "EnvironmentFile", errs[0].Key)
+	assert.Equal(t, V.Location{Line: 3, Column: 16, EndLine: 3, EndColumn: 25}, errs[0].Location)
+	assert.Equal(t, UnexpandedHome, errs[1].ErrorCategory)
+	assert.Equal(t, "Volume", errs[1].Key)
+	// The source of a volume starting with '~' is a named volume so the message does not tell how it is resolved
+	assert.EqualError(t, errs[1].Error, "unexpanded-home: '~' is not expanded in the path '~/data:/data' of key "+
+		"'Container.Volume'. Use the %h specifier instead")
+}
+
+func TestCommonValidator_ValidateTargetedPodmanVersion(t *testing.T) {
+	t.Parallel()
+
+	version := M.PodmanVersion{Name: "v4.9.0", Fields: map[string]map[string]M.Field{
+		"Container": {container.Image.Key: container.Image},
+	}}
+	unit := testutils.ParseString(t, `[Container]
+Image=docker.io/library/app
+ReadOnly=maybe
+Unknown=true`)
+
+	// ReadOnly is not type-checked because it does not exist on the targeted version
+	errs := Validator(V.Options{PodmanVersion: version}).Validate(unit)
+	require.Len(t, errs, 2)
+	assertUnknownKeyError(t, errs[0], 3)
+	assert.ErrorContains(t, errs[0].Error, "key 'ReadOnly' is not supported in group 'Container' by Podman v4.9.0")
+	assertUnknownKeyError(t, errs[1], 4)
+	assert.ErrorContains(t, errs[1].Error, "key 'Unknown' is not allowed in group 'Container'")
+}
+
+func TestCommonValidator_ValidateUnknownKeySuggestions(t *testing.T) {
+	t.Parallel()
+
+	unit := testutils.ParseString(t, `[Container]
+Imgae=docker.io/library/app
+readonly=true
+Unrelated=true`)
+
+	errs := validator.Validate(unit)
+	require.Len(t, errs, 3)
+	assert.ErrorContains(t, errs[0].Error, "key 'Imgae' is not allowed in group 'Container'. Did you mean 'Image'")
+	assert.ErrorContains(t, errs[1].Error, "key 'readonly' is not allowed in group 'Container'. Did you mean 'ReadOnly'")
+	assert.EqualError(t, errs[2].Error, "unknown-key: key 'Unrelated' is not allowed in group 'Container'")
+}
+
+func TestCommonValidator_ValidateSystemdDirectives(t *testing.T) {
+	t.Parallel()
+
+	unit := testutils.ParseString(t, `[Unit]
+Description=app
+Descripton=typo
+
+[Container]
+Image=docker.io/library/app
+
+[Service]
+Restart=always
+TimeoutStartSec=900
+X-Custom=value
+
+[Install]
+WantedBy=default.target
+WantedByy=default.target`)
+
+	errs := validator.Validate(unit)
+	require.Len(t, errs, 2)
+	assertUnknownKeyError(t, errs[0], 3)
+	assert.EqualError(t, errs[0].Error, "unknown-key: key 'Descripton' is not a known directive of systemd "+
+		systemd.Version+" in group 'Unit'. Did you mean 'Description'?")
+	assertUnknownKeyError(t, errs[1], 15)
+	assert.Equal(t, "Install", errs[1].Group)
+}
+
+func TestClosestKey(t *testing.T) {
+	t.Parallel()
+
+	fields := map[string]M.Field{"Label": {}, "Volume": {}, "Pull": {}, "PodmanArgs": {}}
+	tests := map[string]string{
+		"label":   "Label",
+		"Lable":   "Label",
+		"Volumes": "Volume",
+		"Pul":     "Pull",
+		"Poll":    "Pull",
+		"Unknown": "",
+	}
+	for key, expected := range tests {
+		closest, ok := closestKey(key, fields)
+		assert.Equal(t, expected, closest, key)
+		assert.Equal(t, expected != "", ok, key)
+	}
+}
+
+func TestCommonValidator_ValidateUnsupportedOnTarget(t *testing.T) {
+	t.Parallel()
+
+	startWithPod := M.Field{Group: "Container", Key: "StartWithPod", Since: "v5.0.0"}
+	logDestination := M.Field{Group: "Container", Key: "HealthLogDestination", Since: "v5.3.0"}
+	fields := map[string]M.Field{
+		container.Image.Key: container.Image,
+		startWithPod.Key:    startWithPod,
+		logDestination.Key:  logDestination,
+	}
+	unit := testutils.ParseString(t, `[Container]
+Image=docker.io/library/app
+StartWithPod=true
+HealthLogDestination=local`)
+
+	tests := []struct {
+		minimum  string
+		expected []string
+	}{
+		{"", []string{}},
+		{"4.9", []string{"StartWithPod", "HealthLogDestination"}},
+		{"5", []string{"HealthLogDestination"}},
+		{"v5.3.0", []string{}},
+		{"6", []string{}},
+	}
+	for _, test := range tests {
+		validator := commonValidator{context: V.Context{Options: V.Options{MinPodmanVersion: test.minimum}}}
+		errs := validator.unsupportedOnTarget(unit, "Container", fields, fields)
+		assert.Equal(t, test.expected, utils.MapSlice(errs, func(err V.ValidationError) string { return err.Key }),
+			test.minimum)
+	}
+
+	validator := commonValidator{context: V.Context{Options: V.Options{MinPodmanVersion: "4.9"}}}
+	errs := validator.unsupportedOnTarget(unit, "Container", fields, fields)
+	require.Len(t, errs, 2)
+	assert.Equal(t, V.UnsupportedOnTarget, errs[0].ErrorCategory)
+	assert.Equal(t, V.Location{Line: 3, Column: 0, EndLine: 3, EndColumn: 12}, errs[0].Location)
+	assert.ErrorContains(t, errs[0].Error,
+		"key 'Container.StartWithPod' requires Podman v5.0.0 or newer but the minimum targeted version is 4.9")
+
+	// Keys that are not allowed are already reported as unknown
+	errs = validator.unsupportedOnTarget(unit, "Container", map[string]M.Field{}, fields)
+	assert.Empty(t, errs)
+}
+
+func TestCommonValidator_ValidateUnsupportedOnTargetWithGeneratedModel(t *testing.T) {
+	t.Parallel()
+
+	// The first version supporting a key is computed from the older versions generated along with the model
+	assert.NotEmpty(t, container.HealthLogDestination.Since)
+	assert.Empty(t, container.Image.Since)
+
+	unit := testutils.ParseString(t, `[Container]
+Image=docker.io/library/app
+HealthLogDestination=local`)
+
+	errs := Validator(V.Options{MinPodmanVersion: "4.9"}).Validate(unit)
+	require.Len(t, errs, 1)
+	assert.Equal(t, V.UnsupportedOnTarget, errs[0].ErrorCategory)
+	assert.Equal(t, container.HealthLogDestination.Key, errs[0].Key)
+}
+
+func TestCommonValidator_ValidateDeprecatedKeys(t *testing.T) {
+	t.Parallel()
+
+	tests := map[string]string{
+		"app.container": "[Container]\nImage=docker.io/library/app\nVolatileTmp=true\nRemapUidSize=10\nRemapUid=0:1:2",
+		"app.pod":       "[Pod]\nPodName=app\nRemapUsers=auto\nRemapUidSize=10\nRemapUid=0:1:2",
+		"app.kube":      "[Kube]\nYaml=app.yaml\nRemapUsers=auto\nRemapUidSize=10\nRemapUid=0:1:2",
+	}
+	for name, content := range tests {
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			unit, parseErrs := P.ParseUnitFileString(name, content)
+			require.Empty(t, parseErrs)
+
+			errs := validator.Validate(unit)
+			require.Len(t, errs, 3)
+			for i, err := range errs {
+				assert.Equal(t, V.DeprecatedKey, err.ErrorCategory)
+				assert.Equal(t, V.Location{Line: i + 3, Column: 0, EndLine: i + 3, EndColumn: len(err.Key)}, err.Location)
+			}
+		})
+	}
+}
+
 func assertUnknownKeyError(t *testing.T, err V.ValidationError, line int) {
 	t.Helper()
 
@@ -6,7 +6,6 @@ import (
 	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
 	. "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
 	. "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/container"
-	. "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/service"
 	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
 	. "github.com/AhmedMoalla/quadlet-lint/pkg/validator/rules"
 )
@@ -54,28 +53,20 @@ func (v containerValidator) Validate(unit M.UnitFile) []V.ValidationError {
 				HasSuffix(M.UnitTypePod.Ext),
 				CanReference(M.UnitTypePod),
 			),
-			Group: Rules(DependsOn(User)),
 			RemapUid: Rules(
-				Deprecated, ConflictsWithNewUserMappingKeys,
+				ConflictsWithNewUserMappingKeys,
 				DependsOn(RemapUsers),
 				ValuesMust(HaveZeroOrOneValues, WhenFieldEquals(RemapUsers, "keep-id", "auto"),
 					"RemapUsers=keep-id supports only a single value for UID mapping"),
 			),
 			RemapGid: Rules(
-				Deprecated, ConflictsWithNewUserMappingKeys,
+				ConflictsWithNewUserMappingKeys,
 				DependsOn(RemapUsers),
 				ValuesMust(HaveZeroOrOneValues, WhenFieldEquals(RemapUsers, "keep-id", "auto"),
 					"RemapUsers=keep-id supports only a single value for GID mapping"),
 			),
-			RemapUsers: Rules(
-				Deprecated, ConflictsWithNewUserMappingKeys,
-				AllowedValues("manual", "auto", "keep-id"),
-			),
+			RemapUsers:     Rules(ConflictsWithNewUserMappingKeys),
 			ExposeHostPort: Rules(MatchRegexp(exposeHostPortRegexp)),
 		},
-		Service: GService{
-			KillMode: Rules(AllowedValues("mixed", "control-group")),
-			Type:     Rules(AllowedValues("notify", "oneshot")),
-		},
 	})
 }
@@ -0,0 +1,62 @@
+package quadlet
+
+import (
+	"regexp"
+
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	. "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
+	. "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/pod"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/service"
+	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
+	. "github.com/AhmedMoalla/quadlet-lint/pkg/validator/rules"
+)
+
+type podValidator struct {
+	name    string
+	context V.Context
+}
+
+func (v podValidator) Name() string {
+	return v.name
+}
+
+func (v podValidator) Context() V.Context {
+	return v.context
+}
+
+var (
+	podNameRegexp     = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+	publishPortRegexp = regexp.MustCompile(
+		`^((\[[0-9a-fA-F:.]+]|[0-9.]+)?:)?((\d+(-\d+)?)?:)?\d+(-\d+)?(/(tcp|udp|sctp))?$`)
+)
+
+func (v podValidator) Validate(unit M.UnitFile) []V.ValidationError {
+	return CheckRules(v, unit, Groups{
+		Pod: GPod{
+			PodName:     Rules(MatchRegexp(podNameRegexp)),
+			PublishPort: Rules(MatchRegexp(publishPortRegexp)),
+			Network: Rules(
+				CanReference(M.UnitTypeNetwork, M.UnitTypeContainer),
+				MatchRegexp(networkRegexp),
+				HaveFormat(NetworkFormat),
+			),
+			Volume: Rules(CanReference(M.UnitTypeVolume)),
+			RemapUid: Rules(
+				ConflictsWithNewPodUserMappingKeys,
+				DependsOn(RemapUsers),
+				ValuesMust(HaveZeroOrOneValues, WhenFieldEquals(RemapUsers, "keep-id", "auto"),
+					"RemapUsers=keep-id supports only a single value for UID mapping"),
+			),
+			RemapGid: Rules(
+				ConflictsWithNewPodUserMappingKeys,
+				DependsOn(RemapUsers),
+				ValuesMust(HaveZeroOrOneValues, WhenFieldEquals(RemapUsers, "keep-id", "auto"),
+					"RemapUsers=keep-id supports only a single value for GID mapping"),
+			),
+			RemapUsers: Rules(ConflictsWithNewPodUserMappingKeys),
+		},
+		Service: service.GService{
+			Type: Rules(IgnoredOnPod("the service type of a pod is always 'forking'")),
+		},
+	})
+}
@@ -0,0 +1,31 @@
+package quadlet
+
+import (
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	. "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
+	. "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/quadlet"
+	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
+	. "github.com/AhmedMoalla/quadlet-lint/pkg/validator/rules"
+)
+
+// quadletGroupValidator validates the [Quadlet] group which is accepted by every unit type
+type quadletGroupValidator struct {
+	name    string
+	context V.Context
+}
+
+func (v quadletGroupValidator) Name() string {
+	return v.name
+}
+
+func (v quadletGroupValidator) Context() V.Context {
+	return v.context
+}
+
+func (v quadletGroupValidator) Validate(unit M.UnitFile) []V.ValidationError {
+	return CheckRules(v, unit, Groups{
+		Quadlet: GQuadlet{
+			DefaultDependencies: Rules(NoDefaultDependenciesWithReferences),
+		},
+	})
+}
@@ -2,11 +2,15 @@ package quadlet
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 	"unicode"
 
 	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	model "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
 	. "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/container"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/pod"
 	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
 	R "github.com/AhmedMoalla/quadlet-lint/pkg/validator/rules"
 )
@@ -28,6 +32,74 @@ var NetworkFormat = R.Format{
 
 var ConflictsWithNewUserMappingKeys = R.ConflictsWith(UserNS, UIDMap, GIDMap, SubUIDMap, SubGIDMap)
 
+var ConflictsWithNewPodUserMappingKeys = R.ConflictsWith(pod.UserNS, pod.UIDMap, pod.GIDMap, pod.SubUIDMap,
+	pod.SubGIDMap)
+
+// IgnoredOnPod reports every value of a key that ConvertPod overrides with its own value
+func IgnoredOnPod(reason string) V.Rule {
+	return func(validator V.Validator, unit M.UnitFile, field M.Field) []V.ValidationError {
+		res, found := unit.Lookup(field)
+		if !found {
+			return nil
+		}
+
+		validationErrors := make([]V.ValidationError, 0)
+		for _, value := range res.Values() {
+			validationErrors = append(validationErrors, *IgnoredKey.ErrForKey(validator.Name(), "", field,
+				value, fmt.Sprintf("key '%s' is ignored in %s units: %s", field, M.UnitTypePod.Ext, reason)))
+		}
+		return validationErrors
+	}
+}
+
+// NoDefaultDependenciesWithReferences warns when DefaultDependencies=false is set in a unit that references other
+// Quadlet units with keys like Network= or Volume=. Without default dependencies, the ordering of the referenced units
+// is no longer guaranteed.
+func NoDefaultDependenciesWithReferences(validator V.Validator, unit M.UnitFile, field M.Field) []V.ValidationError {
+	res, found := unit.Lookup(field)
+	if !found || res.BoolValue() {
+		return nil
+	}
+
+	references := quadletReferences(unit)
+	if len(references) == 0 {
+		return nil
+	}
+
+	value, _ := res.Value()
+	return []V.ValidationError{*DependencyOrdering.ErrForValue(validator.Name(), "", field, value,
+		fmt.Sprintf("%s=false is set but this unit references the Quadlet units %s. They may not be started "+
+			"before this one", field.Key, references))}
+}
+
+// quadletReferences lists the Quadlet units referenced by name in the keys of a unit whose values can reference other
+// units like Network or Volume
+func quadletReferences(unit M.UnitFile) []string {
+	references := make([]string, 0)
+	fields := model.Fields[unit.UnitType().Group()]
+	for _, key := range slices.Sorted(maps.Keys(fields)) {
+		field := fields[key]
+		if field.Kind != M.KindReference {
+			continue
+		}
+
+		res, found := unit.Lookup(field)
+		if !found {
+			continue
+		}
+
+		for _, value := range res.Values() {
+			name, _, _ := strings.Cut(value.Value, ":")
+			if slices.ContainsFunc(M.AllUnitFileExtensions, func(ext string) bool {
+				return strings.HasSuffix(name, ext)
+			}) {
+				references = append(references, name)
+			}
+		}
+	}
+	return references
+}
+
 func ImageNotAmbiguous(validator V.Validator, unit M.UnitFile, field M.Field) []V.ValidationError {
 	if field.Key != Image.Key {
 		return nil
@@ -40,8 +112,8 @@ func ImageNotAmbiguous(validator V.Validator, unit M.UnitFile, field M.Field) []
 
 	value, ok := res.Value()
 	if !ok {
-		return AmbiguousImageName.ErrSlice(validator.Name(), "empty-value", field, value.Line, value.Column,
-			"value not found")
+		return []V.ValidationError{*AmbiguousImageName.ErrForValue(validator.Name(), "empty-value", field, value,
+			"value not found")}
 	}
 
 	imageName := value.Value
@@ -54,7 +126,7 @@ func ImageNotAmbiguous(validator V.Validator, unit M.UnitFile, field M.Field) []
 		message := fmt.Sprintf("%s specifies the image \"%s\" which not a fully qualified image name. "+
 			"This is not ideal for performance and security reasons. "+
 			"See the podman-pull manpage discussion of short-name-aliases.conf for details.", unit.FileName(), imageName)
-		return AmbiguousImageName.ErrSlice(validator.Name(), "", field, value.Line, value.Column, message)
+		return []V.ValidationError{*AmbiguousImageName.ErrForValue(validator.Name(), "", field, value, message)}
 	}
 
 	return nil
@@ -1,14 +1,24 @@
 package quadlet
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	generated "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/constraints"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/systemd"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/utils"
 	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/validator/rules"
 )
 
 const ValidatorName = "quadlet"
 
 var (
 	AmbiguousImageName = V.NewErrorCategory("ambiguous-image-name", V.LevelWarning)
+	IgnoredKey         = V.NewErrorCategory("ignored-key", V.LevelWarning)
+	DependencyOrdering = V.NewErrorCategory("dependency-ordering", V.LevelWarning)
 )
 
 func Validator(units []model.UnitFile, options V.Options) V.Validator {
@@ -21,20 +31,22 @@ func Validator(units []model.UnitFile, options V.Options) V.Validator {
 		context: context,
 		validators: map[model.UnitType]V.Validator{
 			model.UnitTypeContainer: containerValidator{name: "container", context: context},
-			model.UnitTypeVolume:    noOpValidator{},
-			model.UnitTypeKube:      noOpValidator{},
-			model.UnitTypeNetwork:   noOpValidator{},
-			model.UnitTypeImage:     noOpValidator{},
-			model.UnitTypeBuild:     noOpValidator{},
-			model.UnitTypePod:       noOpValidator{},
+			model.UnitTypeVolume:    noOpValidator{name: "volume", context: context},
+			model.UnitTypeKube:      noOpValidator{name: "kube", context: context},
+			model.UnitTypeNetwork:   noOpValidator{name: "network", context: context},
+			model.UnitTypeImage:     noOpValidator{name: "image", context: context},
+			model.UnitTypeBuild:     noOpValidator{name: "build", context: context},
+			model.UnitTypePod:       podValidator{name: "pod", context: context},
 		},
+		quadletGroup: quadletGroupValidator{name: "quadlet-group", context: context},
 	}
 }
 
 type quadletValidator struct {
-	name       string
-	context    V.Context
-	validators map[model.UnitType]V.Validator
+	name         string
+	context      V.Context
+	validators   map[model.UnitType]V.Validator
+	quadletGroup V.Validator
 }
 
 func (v quadletValidator) Name() string {
@@ -45,18 +57,59 @@ func (v quadletValidator) Context() V.Context {
 	return v.context
 }
 
+// Validate runs the validator of the unit type along with the rules generated from the checks done by Quadlet when
+// converting the unit. Units of an unknown type are skipped.
 func (v quadletValidator) Validate(unit model.UnitFile) []V.ValidationError {
-	return v.validators[unit.UnitType()].Validate(unit)
+	validator, ok := v.validators[unit.UnitType()]
+	if !ok {
+		return []V.ValidationError{}
+	}
+
+	validationErrors := validator.Validate(unit)
+	validationErrors = append(validationErrors,
+		rules.CheckRules(validator, unit, constraints.Rules[unit.UnitType().Name])...)
+	validationErrors = append(validationErrors, v.keysIgnoredByUnitType(unit)...)
+	return append(validationErrors, v.quadletGroup.Validate(unit)...)
+}
+
+// keysIgnoredByUnitType reports the Quadlet keys that the conversion of the unit type does not use like the keys of a
+// [Container] group found in a .pod file. The systemd groups are skipped since they are passed to systemd.
+func (v quadletValidator) keysIgnoredByUnitType(unit model.UnitFile) []V.ValidationError {
+	unitType := unit.UnitType()
+
+	validationErrors := make([]V.ValidationError, 0)
+	for _, group := range unit.ListGroups() {
+		if _, ok := systemd.Fields[group]; ok {
+			continue
+		}
+
+		for _, key := range unit.ListKeys(group) {
+			field, ok := generated.Fields[group][key.Key]
+			if !ok || field.AppliesTo(unitType) {
+				continue
+			}
+
+			extensions := utils.MapSlice(field.UnitTypes, func(name string) string { return "." + name })
+			validationErrors = append(validationErrors, *IgnoredKey.ErrForRange(v.Name(), "", group, key.Key,
+				key.Range, fmt.Sprintf("key '%s' is only used in %s units and is ignored in %s units",
+					field, strings.Join(extensions, ", "), unitType.Ext)))
+		}
+	}
+	return validationErrors
 }
 
-type noOpValidator struct{}
+// noOpValidator is used for the unit types without hand-written rules
+type noOpValidator struct {
+	name    string
+	context V.Context
+}
 
 func (v noOpValidator) Name() string {
-	return "noop"
+	return v.name
 }
 
 func (v noOpValidator) Context() V.Context {
-	return V.Context{}
+	return v.context
 }
 
 func (v noOpValidator) Validate(model.UnitFile) []V.ValidationError {
@@ -5,6 +5,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
 	"github.com/AhmedMoalla/quadlet-lint/pkg/testutils"
 	"github.com/AhmedMoalla/quadlet-lint/pkg/testutils/assertions"
 	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
@@ -37,3 +38,19 @@ func TestContainerValidator_Validate(t *testing.T) {
 		})
 	}
 }
+
+// unknownUnitFile is a unit file whose type has no validator
+type unknownUnitFile struct {
+	M.UnitFile
+}
+
+func (u unknownUnitFile) UnitType() M.UnitType {
+	return M.UnitType{Name: "socket", Ext: ".socket"}
+}
+
+func TestQuadletValidator_ValidateSkipsUnknownUnitType(t *testing.T) {
+	t.Parallel()
+
+	unit := unknownUnitFile{testutils.ParseString(t, "[Socket]\nListenStream=80")}
+	require.Empty(t, Validator(testutils.IncludedTestUnits, V.Options{}).Validate(unit))
+}
@@ -9,6 +9,7 @@ import (
 
 	. "github.com/AhmedMoalla/quadlet-lint/pkg/model"
 	model "github.com/AhmedMoalla/quadlet-lint/pkg/model/generated"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/systemd"
 	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
 )
 
@@ -21,6 +22,7 @@ const (
 	ErrZeroOrOneValue      = "zero-or-one-value"
 	ErrOneRequired         = "one-required"
 	ErrConditionNotMatched = "condition-not-matched"
+	ErrBadType             = "bad-type"
 )
 
 // ================== Utilities ==================
@@ -30,6 +32,22 @@ func Rules(rules ...V.Rule) []V.Rule {
 }
 
 func CheckRules(validator V.Validator, unit UnitFile, rules model.Groups) []V.ValidationError {
+	// The key is reported as unknown when the targeted version does not support it
+	return checkGroupsRules(validator, unit, rules, model.Fields, func(group string) map[string]Field {
+		return TargetFields(validator, group)
+	})
+}
+
+// CheckSystemdRules runs the rules of the systemd directives. They are supported whatever the targeted Podman version.
+func CheckSystemdRules(validator V.Validator, unit UnitFile, rules systemd.Groups) []V.ValidationError {
+	return checkGroupsRules(validator, unit, rules, systemd.Fields, func(group string) map[string]Field {
+		return systemd.Fields[group]
+	})
+}
+
+// checkGroupsRules runs the rules set in the fields of a Groups struct of a model on the supported fields
+func checkGroupsRules(validator V.Validator, unit UnitFile, rules any, fields map[string]map[string]Field,
+	supported func(group string) map[string]Field) []V.ValidationError {
 	validationErrors := make([]V.ValidationError, 0)
 
 	groupsValue := reflect.ValueOf(rules)
@@ -47,12 +65,21 @@ func CheckRules(validator V.Validator, unit UnitFile, rules model.Groups) []V.Va
 			fieldName := fieldType.Name
 
 			ruleFns, _ := groupValue.FieldByName(fieldName).Interface().([]V.Rule)
+			if len(ruleFns) == 0 {
+				continue
+			}
+
+			field, ok := fields[groupName][fieldName]
+			if !ok {
+				panic(fmt.Sprintf("field %s not found in Fields map", fieldName))
+			}
+			field.Group = groupField.Name
+
+			if _, ok := supported(groupName)[fieldName]; !ok {
+				continue
+			}
+
 			for _, rule := range ruleFns {
-				field, ok := model.Fields[groupName][fieldName]
-				if !ok {
-					panic(fmt.Sprintf("field %s not found in Fields map", fieldName))
-				}
-				field.Group = groupField.Name
 				validationErrors = append(validationErrors, rule(validator, unit, field)...)
 			}
 		}
@@ -61,13 +88,38 @@ func CheckRules(validator V.Validator, unit UnitFile, rules model.Groups) []V.Va
 	return validationErrors
 }
 
+// TargetFields returns the keys of a group supported by the Podman version targeted by the validator. They are the
+// keys of the generated model when no version is targeted.
+func TargetFields(validator V.Validator, group string) map[string]Field {
+	if version := validator.Context().PodmanVersion; version.Fields != nil {
+		return version.Fields[group]
+	}
+	return model.Fields[group]
+}
+
+// GroupHeaderRange returns the range of the header of a group to locate the errors about the whole group. The header
+// of the first group is returned when the group is missing, or the start of the first line when the unit has no group.
+func GroupHeaderRange(unit UnitFile, group string) Range {
+	if header, ok := unit.GroupRange(group); ok {
+		return header
+	}
+
+	for _, other := range unit.ListGroups() {
+		if header, ok := unit.GroupRange(other); ok {
+			return header
+		}
+	}
+	return Range{Start: Position{Line: 1}, End: Position{Line: 1}}
+}
+
 // ================== Rules ==================
 
 func RequiredIfNotPresent(other Field) V.Rule {
 	return func(validator V.Validator, unit UnitFile, field Field) []V.ValidationError {
 		if !unit.HasValue(other) && !unit.HasValue(field) {
-			return V.RequiredKey.ErrSlice(validator.Name(), ErrOneRequired, field, 0, 0,
-				fmt.Sprintf("at least one of these keys is required: %s, %s", field, other))
+			header := GroupHeaderRange(unit, field.Group)
+			return []V.ValidationError{*V.RequiredKey.ErrForRange(validator.Name(), ErrOneRequired, field.Group,
+				field.Key, header, fmt.Sprintf("at least one of these keys is required: %s, %s", field, other))}
 		}
 
 		return nil
@@ -81,8 +133,8 @@ func ConflictsWith(others ...Field) V.Rule {
 			if unit.HasValue(other) && unit.HasValue(field) {
 				res, _ := unit.Lookup(field)
 				for _, value := range res.Values() {
-					validationErrors = append(validationErrors, *V.KeyConflict.ErrForField(validator.Name(), "", field,
-						value.Line, 0, fmt.Sprintf("the keys %s, %s cannot be specified together", field, other)))
+					validationErrors = append(validationErrors, *V.KeyConflict.ErrForKey(validator.Name(), "", field,
+						value, fmt.Sprintf("the keys %s, %s cannot be specified together", field, other)))
 				}
 			}
 		}
@@ -108,13 +160,9 @@ func CanReference(unitTypes ...UnitType) V.Rule {
 		for _, value := range res.Values() {
 			for _, unitType := range unitTypes {
 				if strings.HasSuffix(value.Value, unitType.Ext) {
-					foundUnit := slices.ContainsFunc(units, func(unit UnitFile) bool {
-						return unit.FileName() == value.Value
-					})
-
-					if !foundUnit {
-						validationErrors = append(validationErrors, *V.InvalidReference.ErrForField(validator.Name(), "",
-							field, value.Line, value.Column, fmt.Sprintf("requested Quadlet %s '%s' was not found",
+					if _, foundUnit := FindUnitFile(units, value.Value); !foundUnit {
+						validationErrors = append(validationErrors, *V.InvalidReference.ErrForValue(validator.Name(), "",
+							field, value, fmt.Sprintf("requested Quadlet %s '%s' was not found",
 								unitType.Name, value.Value)))
 					}
 					break
@@ -137,8 +185,8 @@ func HaveFormat(format Format) V.Rule {
 		for _, value := range res.Values() {
 			err := format.ParseAndValidate(value.Value)
 			if err != nil {
-				validationErrors = append(validationErrors, *V.InvalidValue.ErrForField(validator.Name(), ErrBadFormat, field,
-					value.Line, value.Column, err.Error()))
+				validationErrors = append(validationErrors, *V.InvalidValue.ErrForValue(validator.Name(), ErrBadFormat, field,
+					value, err.Error()))
 			}
 		}
 
@@ -156,8 +204,8 @@ func AllowedValues(allowedValues ...string) V.Rule {
 		validationErrors := make([]V.ValidationError, 0)
 		for _, value := range res.Values() {
 			if !slices.Contains(allowedValues, value.Value) {
-				validationErrors = append(validationErrors, *V.InvalidValue.ErrForField(validator.Name(), ErrValueNotAllowed, field,
-					value.Line, value.Column, fmt.Sprintf("invalid value '%s' for key '%s'. Allowed values: %s",
+				validationErrors = append(validationErrors, *V.InvalidValue.ErrForValue(validator.Name(), ErrValueNotAllowed, field,
+					value, fmt.Sprintf("invalid value '%s' for key '%s'. Allowed values: %s",
 						value.Value, field, allowedValues)))
 			}
 		}
@@ -175,8 +223,8 @@ func HasSuffix(suffix string) V.Rule {
 		validationErrors := make([]V.ValidationError, 0)
 		for _, value := range res.Values() {
 			if !strings.HasSuffix(value.Value, suffix) {
-				validationErrors = append(validationErrors, *V.InvalidValue.ErrForField(validator.Name(), ErrRequiredSuffix, field,
-					value.Line, value.Column, fmt.Sprintf("value '%s' must have suffix '%s'", value.Value, suffix)))
+				validationErrors = append(validationErrors, *V.InvalidValue.ErrForValue(validator.Name(), ErrRequiredSuffix, field,
+					value, fmt.Sprintf("value '%s' must have suffix '%s'", value.Value, suffix)))
 			}
 		}
 
@@ -195,8 +243,8 @@ func DependsOn(dependency Field) V.Rule {
 		validationErrors := make([]V.ValidationError, 0)
 		if !dependencyOk && fieldOk {
 			for _, value := range res.Values() {
-				validationErrors = append(validationErrors, *V.UnsatisfiedDependency.ErrForField(validator.Name(), "",
-					field, value.Line, 0,
+				validationErrors = append(validationErrors, *V.UnsatisfiedDependency.ErrForKey(validator.Name(), "",
+					field, value,
 					fmt.Sprintf("value for '%s' was set but it depends on key '%s' which was not found",
 						field, dependency.Key)))
 			}
@@ -206,16 +254,35 @@ func DependsOn(dependency Field) V.Rule {
 	}
 }
 
+// Deprecated reports every value of a deprecated key along with the replacement hint of the field if there is one
 func Deprecated(validator V.Validator, unit UnitFile, field Field) []V.ValidationError {
 	res, found := unit.Lookup(field)
 	if !found {
 		return nil
 	}
 
+	message := fmt.Sprintf("key '%s' is deprecated and should not be used", field)
+	if field.Replacement != "" {
+		message += ": " + field.Replacement
+	}
+
 	validationErrors := make([]V.ValidationError, 0)
 	for _, value := range res.Values() {
-		validationErrors = append(validationErrors, *V.DeprecatedKey.ErrForField(validator.Name(), "", field,
-			value.Line, 0, fmt.Sprintf("key '%s' is deprecated and should not be used", field)))
+		validationErrors = append(validationErrors, *V.DeprecatedKey.ErrForKey(validator.Name(), "", field,
+			value, message))
+	}
+	return validationErrors
+}
+
+// HasValidType checks that the value of a boolean or integer key can be converted by its lookup function. For
+// example, it reports 'maybe' for a boolean and '12k' or a negative number for an uint32.
+func HasValidType(validator V.Validator, unit UnitFile, field Field) []V.ValidationError {
+	res, _ := unit.Lookup(field)
+
+	validationErrors := make([]V.ValidationError, 0)
+	for _, err := range res.Errors() {
+		validationErrors = append(validationErrors, *V.InvalidValue.ErrForRange(validator.Name(), ErrBadType,
+			field.Group, field.Key, err.Range, fmt.Sprintf("invalid value for key '%s': %s", field, err)))
 	}
 	return validationErrors
 }
@@ -230,8 +297,8 @@ func MatchRegexp(regex *regexp.Regexp) V.Rule {
 		validationErrors := make([]V.ValidationError, 0)
 		for _, value := range res.Values() {
 			if !regex.MatchString(value.Value) {
-				validationErrors = append(validationErrors, *V.InvalidValue.ErrForField(validator.Name(), ErrNoMatchRegex, field,
-					value.Line, value.Column, fmt.Sprintf("Must match regexp '%s'", regex.String())))
+				validationErrors = append(validationErrors, *V.InvalidValue.ErrForValue(validator.Name(), ErrNoMatchRegex, field,
+					value, fmt.Sprintf("Must match regexp '%s'", regex.String())))
 			}
 		}
 		return validationErrors
@@ -247,13 +314,8 @@ func ValuesMust(valuesPredicate ValuesValidator, rulePredicate RulePredicate, me
 		if res, ok := unit.Lookup(field); ok {
 			if err := valuesPredicate(validator, field, res.Values()); err != nil {
 				errorMsg := buildErrorMessage(messageAndArgs, err)
-				var line, column int
-				if len(res.Values()) > 0 {
-					firstValue := res.Values()[0]
-					line = firstValue.Line
-					column = firstValue.Column
-				}
-				return V.InvalidValue.ErrSlice(validator.Name(), ErrConditionNotMatched, field, line, column, errorMsg)
+				return []V.ValidationError{*V.InvalidValue.ErrForValue(validator.Name(), ErrConditionNotMatched, field,
+					res.Values()[0], errorMsg)}
 			}
 		}
 
@@ -289,7 +351,7 @@ type RulePredicate func(validator V.Validator, unit UnitFile, field Field) bool
 func HaveZeroOrOneValues(validator V.Validator, field Field, values []UnitValue) *V.ValidationError {
 	if len(values) > 1 {
 		value := values[1]
-		return V.InvalidValue.ErrForField(validator.Name(), ErrZeroOrOneValue, field, value.Line, value.Column,
+		return V.InvalidValue.ErrForValue(validator.Name(), ErrZeroOrOneValue, field, value,
 			"should have exactly zero or one value")
 	}
 
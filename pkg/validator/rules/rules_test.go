@@ -12,6 +12,7 @@ import (
 	"github.com/AhmedMoalla/quadlet-lint/pkg/testutils"
 	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 var v = testutils.NewTestValidator(V.Options{})
@@ -35,7 +36,7 @@ func TestCheckRules(t *testing.T) {
 	assert.True(t, slices.ContainsFunc(errs, func(err V.ValidationError) bool {
 		return err.ValidatorName == v.Name() &&
 			err.ErrorCategory == V.RequiredKey &&
-			err.Line == 0 && err.Column == 0
+			err.Line == 1 && err.Column == 0
 	}))
 
 	assert.True(t, slices.ContainsFunc(errs, func(err V.ValidationError) bool {
@@ -45,6 +46,26 @@ func TestCheckRules(t *testing.T) {
 	}))
 }
 
+func TestCheckRulesSkipsFieldsUnsupportedByTargetedVersion(t *testing.T) {
+	t.Parallel()
+
+	validator := testutils.NewTestValidator(V.Options{PodmanVersion: M.PodmanVersion{
+		Name:   "v4.9.0",
+		Fields: map[string]map[string]M.Field{"Service": {service.KillMode.Key: service.KillMode}},
+	}})
+	unit := testutils.ParseString(t, "[Container]\nOther=test\n[Service]\nKillMode=bad")
+	errs := CheckRules(validator, unit, model.Groups{
+		Container: container.GContainer{
+			Rootfs: Rules(RequiredIfNotPresent(container.Image)),
+		},
+		Service: service.GService{
+			KillMode: Rules(AllowedValues("mixed", "control-group")),
+		},
+	})
+	assert.Len(t, errs, 1)
+	assert.Equal(t, V.InvalidValue, errs[0].ErrorCategory)
+}
+
 func TestCheckRulesShouldPanicIfFieldNotGeneratedInModel(t *testing.T) {
 	t.Parallel()
 
@@ -83,14 +104,32 @@ func TestRequiredIfNotPresent(t *testing.T) {
 				for _, err := range errs {
 					assert.Equal(t, v.Name(), err.ValidatorName)
 					assert.Equal(t, V.RequiredKey, err.ErrorCategory)
-					assert.Equal(t, 0, err.Line)
-					assert.Equal(t, 0, err.Column)
+					// The error points at the group header
+					assert.Equal(t, V.Location{Line: 1, Column: 0, EndLine: 1, EndColumn: 11}, err.Location)
 				}
 			}
 		})
 	}
 }
 
+func TestRequiredIfNotPresentWithoutGroup(t *testing.T) {
+	t.Parallel()
+
+	rule := RequiredIfNotPresent(container.Image)
+
+	// The error points at the first group when the group of the field is missing
+	unit := testutils.ParseString(t, "# web\n[Unit]\nDescription=web")
+	errs := rule(v, unit, container.Rootfs)
+	require.Len(t, errs, 1)
+	assert.Equal(t, V.Location{Line: 2, Column: 0, EndLine: 2, EndColumn: 6}, errs[0].Location)
+
+	// The error points at the first line when the unit has no group
+	unit = testutils.ParseString(t, "# web")
+	errs = rule(v, unit, container.Rootfs)
+	require.Len(t, errs, 1)
+	assert.Equal(t, V.Location{Line: 1, Column: 0, EndLine: 1, EndColumn: 0}, errs[0].Location)
+}
+
 func TestConflictsWith(t *testing.T) {
 	t.Parallel()
 
@@ -130,7 +169,8 @@ func TestConflictsWith(t *testing.T) {
 func TestCanReference(t *testing.T) {
 	t.Parallel()
 
-	vRef := testutils.NewTestValidator(V.Options{CheckReferences: true}, "test.network", "test.container")
+	vRef := testutils.NewTestValidator(V.Options{CheckReferences: true}, "test.network", "test.container",
+		"app@.network", "db@blue.network")
 	tests := []struct {
 		name      string
 		unit      string
@@ -141,6 +181,9 @@ func TestCanReference(t *testing.T) {
 		{"NoErrorsIfFieldAbsent", "[Container]\nOther=5", vRef, nil},
 		{"ReferencesCorrectly", "[Container]\nNetwork=test.network", vRef, nil},
 		{"ReferencesCorrectly2", "[Container]\nNetwork=test.container", vRef, nil},
+		{"ReferencesInstanceOfTemplate", "[Container]\nNetwork=app@blue.network", vRef, nil},
+		{"ReferencesInstanceWithoutTemplate", "[Container]\nNetwork=db@blue.network", vRef, nil},
+		{"BadInstanceReference", "[Container]\nNetwork=db@green.network", vRef, []V.Location{{Line: 2, Column: 8}}},
 		{"BadReference", "[Container]\nNetwork=bad.container", vRef, []V.Location{{Line: 2, Column: 8}}},
 		{"BadReferences", "[Container]\nNetwork=bad.container\nOther=6\nNetwork=otherbad.network", vRef,
 			[]V.Location{{Line: 2, Column: 8}, {Line: 4, Column: 8}}},
@@ -219,9 +262,9 @@ func TestAllowedValues(t *testing.T) {
 		{"FieldHasSomeAllowedValues", "[Container]\nPublishPort=val1\nPublishPort=val2", nil},
 		{"FieldHasAllAllowedValues", "[Container]\nPublishPort=val1\nPublishPort=val2\nPublishPort=val3", nil},
 		{"FieldHasBadValues", "[Container]\nPublishPort=bad\nPublishPort=bad2",
-			[]V.Location{{Line: 2, Column: 12}, {Line: 3, Column: 12}}},
+			[]V.Location{{Line: 2, Column: 12, EndLine: 2, EndColumn: 15}, {Line: 3, Column: 12, EndLine: 3, EndColumn: 16}}},
 		{"FieldHasSomeBadValues", "[Container]\nPublishPort=bad\nOther=test\nPublishPort=val2\nPublishPort=bad2",
-			[]V.Location{{Line: 2, Column: 12}, {Line: 5, Column: 12}}},
+			[]V.Location{{Line: 2, Column: 12, EndLine: 2, EndColumn: 15}, {Line: 5, Column: 12, EndLine: 5, EndColumn: 16}}},
 	}
 
 	rule := AllowedValues("val1", "val2", "val3")
@@ -237,8 +280,7 @@ func TestAllowedValues(t *testing.T) {
 				for i, err := range errs {
 					assert.Equal(t, v.Name(), err.ValidatorName)
 					assert.Equal(t, V.InvalidValue, err.ErrorCategory)
-					assert.Equal(t, test.errors[i].Line, err.Line)
-					assert.Equal(t, test.errors[i].Column, err.Column)
+					assert.Equal(t, test.errors[i], err.Location)
 				}
 			}
 		})
@@ -347,6 +389,18 @@ func TestDeprecated(t *testing.T) {
 	}
 }
 
+func TestDeprecatedWithReplacement(t *testing.T) {
+	t.Parallel()
+
+	field := container.RemapUsers
+	field.Replacement = "use UserNS instead"
+	unit := testutils.ParseString(t, "[Container]\nRemapUsers=auto")
+	errs := Deprecated(v, unit, field)
+	require.Len(t, errs, 1)
+	assert.ErrorContains(t, errs[0].Error,
+		"key 'Container.RemapUsers' is deprecated and should not be used: use UserNS instead")
+}
+
 func TestMatchRegexp(t *testing.T) {
 	t.Parallel()
 
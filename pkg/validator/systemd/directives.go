@@ -0,0 +1,72 @@
+package systemd
+
+import (
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/systemd"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/systemd/install"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/systemd/service"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/systemd/unit"
+	. "github.com/AhmedMoalla/quadlet-lint/pkg/validator/rules"
+)
+
+// ================== [Service] ==================
+
+// serviceRules check the values of the directives of systemd.service(5), systemd.exec(5), systemd.kill(5) and
+// systemd.resource-control(5) accepted in the [Service] group
+var serviceRules = systemd.Groups{Service: service.GService{
+	Type: Rules(AllowedValues("simple", "exec", "forking", "oneshot", "dbus", "notify", "notify-reload",
+		"idle")),
+	Restart: Rules(AllowedValues("no", "always", "on-success", "on-failure", "on-abnormal", "on-abort",
+		"on-watchdog")),
+	RestartSec:        Rules(IsTimeSpan),
+	TimeoutStartSec:   Rules(IsTimeSpan),
+	TimeoutStopSec:    Rules(IsTimeSpan),
+	TimeoutAbortSec:   Rules(IsTimeSpan),
+	TimeoutSec:        Rules(IsTimeSpan),
+	RuntimeMaxSec:     Rules(IsTimeSpan),
+	WatchdogSec:       Rules(IsTimeSpan),
+	ExecStart:         Rules(GeneratedByQuadlet(M.AllUnitTypes...)),
+	ExecStop:          Rules(GeneratedByQuadlet(M.UnitTypeContainer, M.UnitTypePod)),
+	Environment:       Rules(NotPassedToPodman(M.UnitTypeContainer, "Container.Environment")),
+	NotifyAccess:      Rules(AllowedValues("none", "main", "exec", "all")),
+	OOMPolicy:         Rules(AllowedValues("continue", "stop", "kill")),
+	KillMode:          Rules(AllowedValues("control-group", "mixed", "process", "none")),
+	RestartMode:       Rules(AllowedValues("normal", "direct")),
+	ExitType:          Rules(AllowedValues("main", "cgroup")),
+	TimeoutCleanSec:   Rules(IsTimeSpan),
+	CPUQuotaPeriodSec: Rules(IsTimeSpan),
+}}
+
+// ================== [Unit] ==================
+
+// unitRules check the values of the directives of systemd.unit(5) accepted in the [Unit] group
+var unitRules = systemd.Groups{Unit: unit.GUnit{
+	Wants:                 Rules(CanReferenceUnits),
+	Requires:              Rules(CanReferenceUnits),
+	Requisite:             Rules(CanReferenceUnits),
+	BindsTo:               Rules(CanReferenceUnits),
+	PartOf:                Rules(CanReferenceUnits),
+	Upholds:               Rules(CanReferenceUnits),
+	Conflicts:             Rules(CanReferenceUnits),
+	Before:                Rules(CanReferenceUnits),
+	After:                 Rules(CanReferenceUnits),
+	OnFailure:             Rules(CanReferenceUnits),
+	OnSuccess:             Rules(CanReferenceUnits),
+	JobTimeoutSec:         Rules(IsTimeSpan),
+	JobRunningTimeoutSec:  Rules(IsTimeSpan),
+	StartLimitIntervalSec: Rules(IsTimeSpan),
+	CollectMode:           Rules(AllowedValues("inactive", "inactive-or-failed")),
+	OnFailureJobMode: Rules(AllowedValues("fail", "replace", "replace-irreversibly", "isolate", "flush",
+		"ignore-dependencies", "ignore-requirements")),
+}}
+
+// ================== [Install] ==================
+
+// installRules check the values of the directives of systemd.unit(5) accepted in the [Install] group
+var installRules = systemd.Groups{Install: install.GInstall{
+	WantedBy:   Rules(AreUnitNames),
+	RequiredBy: Rules(AreUnitNames),
+	UpheldBy:   Rules(AreUnitNames),
+	Alias:      Rules(NotSupportedByQuadlet),
+	Also:       Rules(NotSupportedByQuadlet),
+}}
@@ -0,0 +1,295 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/lookup"
+	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
+)
+
+const (
+	ErrBadTimeSpan        = "bad-time-span"
+	ErrGeneratedByQuadlet = "generated-by-quadlet"
+	ErrNotPassedToPodman  = "not-passed-to-podman"
+	ErrUnknownService     = "unknown-generated-service"
+	ErrBadUnitName        = "bad-unit-name"
+)
+
+// ================== Rules ==================
+
+// IsTimeSpan checks that values are time spans as parsed by systemd's parse_sec()
+// For example: 900, 5min 20s, 1h30m or infinity
+func IsTimeSpan(validator V.Validator, unit M.UnitFile, field M.Field) []V.ValidationError {
+	res, found := unit.Lookup(field)
+	if !found {
+		return nil
+	}
+
+	validationErrors := make([]V.ValidationError, 0)
+	for _, value := range res.Values() {
+		if err := parseTimeSpan(value.Value); err != nil {
+			validationErrors = append(validationErrors, *V.InvalidValue.ErrForValue(validator.Name(), ErrBadTimeSpan, field,
+				value, fmt.Sprintf("invalid time span '%s' for key '%s': %s", value.Value, field, err)))
+		}
+	}
+	return validationErrors
+}
+
+// GeneratedByQuadlet reports a key that Quadlet already generates for the given unit types.
+// Setting it again adds a second command to the generated service which systemd either rejects or
+// runs alongside the podman command.
+func GeneratedByQuadlet(unitTypes ...M.UnitType) V.Rule {
+	return func(validator V.Validator, unit M.UnitFile, field M.Field) []V.ValidationError {
+		if !slices.Contains(unitTypes, unit.UnitType()) {
+			return nil
+		}
+
+		res, found := unit.Lookup(field)
+		if !found {
+			return nil
+		}
+
+		validationErrors := make([]V.ValidationError, 0)
+		for _, value := range res.Values() {
+			validationErrors = append(validationErrors, *V.KeyConflict.ErrForKey(validator.Name(), ErrGeneratedByQuadlet,
+				field, value, fmt.Sprintf("key '%s' clashes with the command generated by Quadlet for %s units",
+					field, unit.UnitType().Ext)))
+		}
+		return validationErrors
+	}
+}
+
+// NotPassedToPodman warns about a service key whose values never reach the container of the given unit type
+func NotPassedToPodman(unitType M.UnitType, replacement string) V.Rule {
+	return func(validator V.Validator, unit M.UnitFile, field M.Field) []V.ValidationError {
+		if unit.UnitType() != unitType {
+			return nil
+		}
+
+		res, found := unit.Lookup(field)
+		if !found {
+			return nil
+		}
+
+		validationErrors := make([]V.ValidationError, 0)
+		for _, value := range res.Values() {
+			validationErrors = append(validationErrors, *IneffectiveKey.ErrForKey(validator.Name(), ErrNotPassedToPodman,
+				field, value, fmt.Sprintf("key '%s' only applies to the podman process and does not reach the "+
+					"container. Use '%s' instead", field, replacement)))
+		}
+		return validationErrors
+	}
+}
+
+// CanReferenceUnits checks that the units named in a dependency directive like After= or Requires= can be
+// resolved when they are Quadlet files or services generated by Quadlet
+func CanReferenceUnits(validator V.Validator, unit M.UnitFile, field M.Field) []V.ValidationError {
+	context := validator.Context()
+	if !context.CheckReferences {
+		return nil
+	}
+
+	// Dependencies are lists of unit names separated by whitespace
+	field.LookupFunc = lookup.LookupAllStrv
+	res, found := unit.Lookup(field)
+	if !found {
+		return nil
+	}
+
+	units := context.AllUnitFiles
+	services := generatedServiceNames(units)
+	validationErrors := make([]V.ValidationError, 0)
+	for _, value := range res.Values() {
+		name := value.Value
+		// Specifiers are only resolved by systemd
+		if strings.Contains(name, "%") {
+			continue
+		}
+
+		ext := filepath.Ext(name)
+		switch {
+		case slices.Contains(M.AllUnitFileExtensions, ext):
+			if _, foundUnit := M.FindUnitFile(units, name); !foundUnit {
+				validationErrors = append(validationErrors, *V.InvalidReference.ErrForValue(validator.Name(), "",
+					field, value, fmt.Sprintf("requested Quadlet %s '%s' was not found",
+						ext[1:], name)))
+			}
+		case ext == ".service" && looksGeneratedByQuadlet(name):
+			if !hasGeneratedService(services, name) {
+				validationErrors = append(validationErrors, *V.InvalidReference.ErrForValue(validator.Name(),
+					ErrUnknownService, field, value, fmt.Sprintf("'%s' looks like a service "+
+						"generated by Quadlet but no Quadlet file generates it", name)))
+			}
+		}
+	}
+
+	return validationErrors
+}
+
+var unitNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9:_.\\%-]+(@[a-zA-Z0-9:_.\\%-]*)?` +
+	`\.(service|socket|device|mount|automount|swap|target|path|timer|slice|scope)$`)
+
+// AreUnitNames checks that every word of the values is a valid unit name like multi-user.target
+func AreUnitNames(validator V.Validator, unit M.UnitFile, field M.Field) []V.ValidationError {
+	field.LookupFunc = lookup.LookupAllStrv
+	res, found := unit.Lookup(field)
+	if !found {
+		return nil
+	}
+
+	validationErrors := make([]V.ValidationError, 0)
+	for _, value := range res.Values() {
+		if !unitNameRegexp.MatchString(value.Value) {
+			validationErrors = append(validationErrors, *V.InvalidValue.ErrForValue(validator.Name(), ErrBadUnitName,
+				field, value, fmt.Sprintf("'%s' is not a valid unit name. Expected a name like "+
+					"'default.target' or 'other.service'", value.Value)))
+		}
+	}
+	return validationErrors
+}
+
+// NotSupportedByQuadlet warns about directives that are dropped or ignored for services generated by Quadlet
+func NotSupportedByQuadlet(validator V.Validator, unit M.UnitFile, field M.Field) []V.ValidationError {
+	res, found := unit.Lookup(field)
+	if !found {
+		return nil
+	}
+
+	validationErrors := make([]V.ValidationError, 0)
+	for _, value := range res.Values() {
+		validationErrors = append(validationErrors, *UnsupportedKey.ErrForKey(validator.Name(), "", field,
+			value, fmt.Sprintf("key '%s' is not supported by Quadlet and will be ignored", field)))
+	}
+	return validationErrors
+}
+
+// generatedServiceSuffixes are the suffixes Quadlet appends to the name of a unit file to name its service.
+// See getServiceName in podman's quadlet package.
+var generatedServiceSuffixes = map[M.UnitType]string{
+	M.UnitTypeContainer: "",
+	M.UnitTypeKube:      "",
+	M.UnitTypeVolume:    "-volume",
+	M.UnitTypeNetwork:   "-network",
+	M.UnitTypeImage:     "-image",
+	M.UnitTypeBuild:     "-build",
+	M.UnitTypePod:       "-pod",
+}
+
+func generatedServiceNames(units []M.UnitFile) map[string]M.UnitFile {
+	services := make(map[string]M.UnitFile, len(units))
+	for _, unit := range units {
+		unitType := unit.UnitType()
+		suffix, ok := generatedServiceSuffixes[unitType]
+		if !ok {
+			continue
+		}
+
+		name := strings.TrimSuffix(unit.FileName(), unitType.Ext) + suffix
+		serviceNameField := M.Field{Group: unitType.Group(), Key: "ServiceName", LookupFunc: lookup.Lookup}
+		if res, ok := unit.Lookup(serviceNameField); ok {
+			if value, ok := res.Value(); ok {
+				name = value.Value
+			}
+		}
+
+		services[name+".service"] = unit
+	}
+	return services
+}
+
+// hasGeneratedService is true if a service is generated by Quadlet. An instance like web@blue.service is generated
+// by the template web@.container and an instance like net@blue-network.service by the template net@.network whose
+// service is net@-network.service.
+func hasGeneratedService(services map[string]M.UnitFile, name string) bool {
+	if _, ok := services[name]; ok {
+		return true
+	}
+
+	unitName := M.ParseUnitName(name)
+	if !unitName.IsInstance() {
+		return false
+	}
+
+	for _, suffix := range generatedServiceSuffixes {
+		instance, found := strings.CutSuffix(unitName.Instance, suffix)
+		if !found || instance == "" {
+			continue
+		}
+
+		if _, ok := services[unitName.Prefix+"@"+suffix+unitName.Ext]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// looksGeneratedByQuadlet is true for service names like mynet-network.service that end with a suffix only used
+// by Quadlet. Services of containers and kube units cannot be told apart from other services.
+func looksGeneratedByQuadlet(name string) bool {
+	name = strings.TrimSuffix(name, ".service")
+	for _, suffix := range generatedServiceSuffixes {
+		if suffix != "" && strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// ================== Time spans ==================
+
+var (
+	errEmptyTimeSpan       = errors.New("empty time span")
+	errInvalidTimeSpanUnit = errors.New("invalid time unit")
+	errInvalidTimeSpan     = errors.New("expected a number optionally followed by a time unit")
+)
+
+const timeSpanInfinity = "infinity"
+
+var timeSpanRegexp = regexp.MustCompile(`^([0-9]+(?:\.[0-9]*)?|\.[0-9]+)\s*([a-zA-Zµμ]*)`)
+
+// timeSpanUnits are the units accepted by systemd's parse_time()
+var timeSpanUnits = []string{
+	"usec", "us", "µs", "μs",
+	"nsec", "ns",
+	"msec", "ms",
+	"seconds", "second", "sec", "s",
+	"minutes", "minute", "min", "m",
+	"hours", "hour", "hr", "h",
+	"days", "day", "d",
+	"weeks", "week", "w",
+	"months", "month", "M",
+	"years", "year", "y",
+}
+
+func parseTimeSpan(value string) error {
+	value = strings.TrimSpace(value)
+	if value == timeSpanInfinity {
+		return nil
+	}
+
+	if len(value) == 0 {
+		return errEmptyTimeSpan
+	}
+
+	for len(value) > 0 {
+		match := timeSpanRegexp.FindStringSubmatch(value)
+		if match == nil {
+			return fmt.Errorf("%w at '%s'", errInvalidTimeSpan, value)
+		}
+
+		unit := match[2]
+		if unit != "" && !slices.Contains(timeSpanUnits, unit) {
+			return fmt.Errorf("%w '%s'. Allowed units: %s", errInvalidTimeSpanUnit, unit, timeSpanUnits)
+		}
+
+		value = strings.TrimLeft(value[len(match[0]):], " \t")
+	}
+
+	return nil
+}
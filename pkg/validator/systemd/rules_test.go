@@ -0,0 +1,42 @@
+package systemd
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTimeSpan(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"900", true},
+		{"0", true},
+		{"1.5", true},
+		{"5min", true},
+		{"5min 20s", true},
+		{"1h30m", true},
+		{"2 weeks", true},
+		{"500ms", true},
+		{"10µs", true},
+		{"infinity", true},
+		{"", false},
+		{"-5s", false},
+		{"10x", false},
+		{"5 minuts", false},
+		{"forever", false},
+		{"5min,20s", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.value, func(t *testing.T) {
+			t.Parallel()
+
+			err := parseTimeSpan(test.value)
+			assert.Equal(t, test.valid, err == nil, err)
+		})
+	}
+}
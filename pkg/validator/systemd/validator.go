@@ -0,0 +1,120 @@
+package systemd
+
+import (
+	"fmt"
+
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/service"
+	"github.com/AhmedMoalla/quadlet-lint/pkg/model/generated/systemd"
+	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
+	R "github.com/AhmedMoalla/quadlet-lint/pkg/validator/rules"
+)
+
+const ValidatorName = "systemd"
+
+var (
+	IneffectiveKey   = V.NewErrorCategory("ineffective-key", V.LevelWarning)
+	UnsupportedKey   = V.NewErrorCategory("unsupported-key", V.LevelWarning)
+	NoInstallSection = V.NewErrorCategory("no-install-section", V.LevelWarning)
+)
+
+func Validator(units []M.UnitFile, options V.Options) V.Validator {
+	context := V.Context{
+		AllUnitFiles: units,
+		Options:      options,
+	}
+	return systemdValidator{
+		name:    ValidatorName,
+		context: context,
+		validators: []V.Validator{
+			groupValidator{name: "unit", context: context, group: "Unit", rules: unitRules},
+			groupValidator{name: "service", context: context, group: "Service", rules: serviceRules},
+			installValidator{
+				groupValidator: groupValidator{name: "install", context: context, group: "Install", rules: installRules},
+			},
+		},
+	}
+}
+
+type systemdValidator struct {
+	name       string
+	context    V.Context
+	validators []V.Validator
+}
+
+func (v systemdValidator) Name() string {
+	return v.name
+}
+
+func (v systemdValidator) Context() V.Context {
+	return v.context
+}
+
+func (v systemdValidator) Validate(unit M.UnitFile) []V.ValidationError {
+	validationErrors := make([]V.ValidationError, 0)
+	for _, validator := range v.validators {
+		validationErrors = append(validationErrors, validator.Validate(unit)...)
+	}
+	return validationErrors
+}
+
+// groupValidator checks the directives of a group. Their rules are set in the group of the systemd model and the
+// unknown directives are reported by the common validator.
+type groupValidator struct {
+	name    string
+	context V.Context
+	group   string
+	rules   systemd.Groups
+}
+
+func (v groupValidator) Name() string {
+	return v.name
+}
+
+func (v groupValidator) Context() V.Context {
+	return v.context
+}
+
+func (v groupValidator) Validate(unit M.UnitFile) []V.ValidationError {
+	if !unit.HasGroup(v.group) {
+		return nil
+	}
+
+	return R.CheckSystemdRules(v, unit, v.rules)
+}
+
+type installValidator struct {
+	groupValidator
+}
+
+func (v installValidator) Validate(unit M.UnitFile) []V.ValidationError {
+	validationErrors := v.groupValidator.Validate(unit)
+
+	group := v.group
+	if v.context.CheckInstallSection && !unit.HasGroup(group) && looksLongRunning(unit) {
+		// The error points at the group of the unit type like [Container]
+		header := R.GroupHeaderRange(unit, unit.UnitType().Group())
+		validationErrors = append(validationErrors, *NoInstallSection.ErrForRange(v.Name(), "", group, "", header,
+			fmt.Sprintf("%s has no [%s] section so it will never be started at boot. "+
+				"Add 'WantedBy=default.target' to start it automatically", unit.FileName(), group)))
+	}
+
+	return validationErrors
+}
+
+// looksLongRunning is true for units running containers in a service that is not a oneshot
+func looksLongRunning(unit M.UnitFile) bool {
+	switch unit.UnitType() {
+	case M.UnitTypeContainer, M.UnitTypeKube, M.UnitTypePod:
+	default:
+		return false
+	}
+
+	if res, ok := unit.Lookup(service.Type); ok {
+		if value, ok := res.Value(); ok && value.Value == "oneshot" {
+			return false
+		}
+	}
+
+	return true
+}
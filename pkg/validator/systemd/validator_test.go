@@ -0,0 +1,253 @@
+package systemd
+
+import (
+	"testing"
+
+	M "github.com/AhmedMoalla/quadlet-lint/pkg/model"
+	P "github.com/AhmedMoalla/quadlet-lint/pkg/parser"
+	V "github.com/AhmedMoalla/quadlet-lint/pkg/validator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const serviceToTest = `[Container]
+Image=docker.io/library/nginx
+
+[Service]
+Restrat=always
+Restart=sometimes
+Type=forked
+TimeoutStartSec=5 minutes
+RestartSec=10x
+ExecStart=/usr/bin/true
+ExecStop=/usr/bin/true
+Environment=FOO=bar
+X-Custom=value
+`
+
+func TestServiceValidator_Validate(t *testing.T) {
+	t.Parallel()
+
+	unit := parse(t, "test.container", serviceToTest)
+	errs := Validator([]M.UnitFile{unit}, V.Options{}).Validate(unit)
+
+	expected := []struct {
+		category V.ErrorCategory
+		key      string
+		line     int
+		column   int
+	}{
+		{V.InvalidValue, "Restart", 6, 8},
+		{V.InvalidValue, "Type", 7, 5},
+		{V.InvalidValue, "RestartSec", 9, 11},
+		{V.KeyConflict, "ExecStart", 10, 0},
+		{V.KeyConflict, "ExecStop", 11, 0},
+		{IneffectiveKey, "Environment", 12, 0},
+	}
+
+	require.Len(t, errs, len(expected), errs)
+	for _, exp := range expected {
+		err := findError(t, errs, exp.key)
+		assert.Equal(t, "service", err.ValidatorName)
+		assert.Equal(t, "Service", err.Group)
+		assert.Equal(t, exp.category, err.ErrorCategory, exp.key)
+		assert.Equal(t, exp.line, err.Line, exp.key)
+		assert.Equal(t, exp.column, err.Column, exp.key)
+	}
+}
+
+func TestServiceValidator_ValidateDependsOnUnitType(t *testing.T) {
+	t.Parallel()
+
+	unit := parse(t, "test.network", "[Network]\n[Service]\nExecStop=/usr/bin/true\nEnvironment=FOO=bar")
+	errs := Validator([]M.UnitFile{unit}, V.Options{}).Validate(unit)
+	assert.Empty(t, errs)
+
+	unit = parse(t, "test.network", "[Network]\n[Service]\nExecStart=/usr/bin/true")
+	errs = Validator([]M.UnitFile{unit}, V.Options{}).Validate(unit)
+	require.Len(t, errs, 1)
+	assert.Equal(t, ErrGeneratedByQuadlet, errs[0].ErrorName)
+}
+
+func parse(t *testing.T, filename, content string) M.UnitFile {
+	t.Helper()
+
+	unit, errs := P.ParseUnitFileString(filename, content)
+	require.Empty(t, errs)
+	return unit
+}
+
+func findError(t *testing.T, errs []V.ValidationError, key string) V.ValidationError {
+	t.Helper()
+
+	for _, err := range errs {
+		if err.Key == key {
+			return err
+		}
+	}
+
+	t.Fatalf("no error found for key %s", key)
+	return V.ValidationError{}
+}
+
+const unitToTest = `[Unit]
+Descripton=typo
+After=db.container network-online.target
+Requires=mynet.network
+Wants=missing.container
+BindsTo=mynet-network.service
+PartOf=other-network.service
+After=custom-db.service sshd.service %i.container
+JobTimeoutSec=forever
+
+[Container]
+Image=docker.io/library/nginx
+`
+
+func TestUnitValidator_Validate(t *testing.T) {
+	t.Parallel()
+
+	unit := parse(t, "web.container", unitToTest)
+	units := []M.UnitFile{
+		unit,
+		parse(t, "db.container", "[Container]\nImage=docker.io/library/postgres\nServiceName=custom-db"),
+		parse(t, "mynet.network", "[Network]"),
+	}
+
+	errs := Validator(units, V.Options{CheckReferences: true}).Validate(unit)
+
+	expected := []struct {
+		category V.ErrorCategory
+		key      string
+		line     int
+		column   int
+	}{
+		{V.InvalidReference, "Wants", 5, 6},
+		{V.InvalidReference, "PartOf", 7, 7},
+		{V.InvalidValue, "JobTimeoutSec", 9, 14},
+	}
+
+	require.Len(t, errs, len(expected), errs)
+	for _, exp := range expected {
+		err := findError(t, errs, exp.key)
+		assert.Equal(t, "unit", err.ValidatorName)
+		assert.Equal(t, "Unit", err.Group)
+		assert.Equal(t, exp.category, err.ErrorCategory, exp.key)
+		assert.Equal(t, exp.line, err.Line, exp.key)
+		assert.Equal(t, exp.column, err.Column, exp.key)
+	}
+
+	errs = Validator(units, V.Options{CheckReferences: false}).Validate(unit)
+	assert.Len(t, errs, 1)
+}
+
+func TestUnitValidator_ValidateTemplateReferences(t *testing.T) {
+	t.Parallel()
+
+	unit := parse(t, "web.container",
+		"[Unit]\nAfter=app@blue.container app@blue.service\nWants=cache@blue.container\n"+
+			"Requires=net@blue-network.service data@blue-volume.service\n[Container]\nImage=web")
+	units := []M.UnitFile{
+		unit,
+		parse(t, "app@.container", "[Container]\nImage=docker.io/library/app"),
+		parse(t, "net@.network", "[Network]"),
+	}
+
+	errs := Validator(units, V.Options{CheckReferences: true}).Validate(unit)
+	require.Len(t, errs, 2)
+	assert.Equal(t, V.InvalidReference, findError(t, errs, "Wants").ErrorCategory)
+	// Only the volume has no template generating its service
+	err := findError(t, errs, "Requires")
+	assert.Equal(t, V.InvalidReference, err.ErrorCategory)
+	assert.ErrorContains(t, err.Error, "data@blue-volume.service")
+}
+
+func TestInstallValidator_Validate(t *testing.T) {
+	t.Parallel()
+
+	unit := parse(t, "web.container", `[Container]
+Image=docker.io/library/nginx
+
+[Install]
+WantedBy=multi-user.target default
+RequiredBy=other@blue.service
+Alias=web.service
+Also=other.service
+`)
+
+	errs := Validator([]M.UnitFile{unit}, V.Options{}).Validate(unit)
+
+	expected := []struct {
+		category V.ErrorCategory
+		key      string
+		line     int
+		column   int
+	}{
+		{V.InvalidValue, "WantedBy", 5, 27},
+		{UnsupportedKey, "Alias", 7, 0},
+		{UnsupportedKey, "Also", 8, 0},
+	}
+
+	require.Len(t, errs, len(expected), errs)
+	for _, exp := range expected {
+		err := findError(t, errs, exp.key)
+		assert.Equal(t, "install", err.ValidatorName)
+		assert.Equal(t, "Install", err.Group)
+		assert.Equal(t, exp.category, err.ErrorCategory, exp.key)
+		assert.Equal(t, exp.line, err.Line, exp.key)
+		assert.Equal(t, exp.column, err.Column, exp.key)
+	}
+}
+
+func TestInstallValidator_ValidateNoInstallSection(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		options  V.Options
+		nErrors  int
+	}{
+		{"DisabledByDefault", "web.container", "[Container]\nImage=nginx", V.Options{}, 0},
+		{"LongRunningContainer", "web.container", "[Container]\nImage=nginx", V.Options{CheckInstallSection: true}, 1},
+		{"LongRunningPod", "web.pod", "[Pod]", V.Options{CheckInstallSection: true}, 1},
+		{"OneshotContainer", "web.container", "[Container]\nImage=nginx\n[Service]\nType=oneshot",
+			V.Options{CheckInstallSection: true}, 0},
+		{"Network", "web.network", "[Network]", V.Options{CheckInstallSection: true}, 0},
+		{"WithInstallSection", "web.container", "[Container]\nImage=nginx\n[Install]\nWantedBy=default.target",
+			V.Options{CheckInstallSection: true}, 0},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			t.Parallel()
+
+			unit := parse(t, test.filename, test.content)
+			errs := Validator([]M.UnitFile{unit}, test.options).Validate(unit)
+			require.Len(t, errs, test.nErrors, errs)
+			for _, err := range errs {
+				assert.Equal(t, NoInstallSection, err.ErrorCategory)
+				assert.Equal(t, "Install", err.Group)
+			}
+		})
+	}
+}
+
+func TestInstallValidator_ValidateNoInstallSectionLocation(t *testing.T) {
+	t.Parallel()
+
+	options := V.Options{CheckInstallSection: true}
+
+	// The error points at the group of the unit type
+	unit := parse(t, "web.container", "[Unit]\nDescription=web\n\n[Container]\nImage=nginx")
+	errs := Validator([]M.UnitFile{unit}, options).Validate(unit)
+	require.Len(t, errs, 1)
+	assert.Equal(t, V.Location{Line: 4, Column: 0, EndLine: 4, EndColumn: 11}, errs[0].Location)
+
+	// The error points at the first line when the unit has no group
+	unit = parse(t, "web.container", "")
+	errs = Validator([]M.UnitFile{unit}, options).Validate(unit)
+	require.Len(t, errs, 1)
+	assert.Equal(t, V.Location{Line: 1, Column: 0, EndLine: 1, EndColumn: 0}, errs[0].Location)
+}
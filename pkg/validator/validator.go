@@ -20,7 +20,14 @@ type Context struct {
 }
 
 type Options struct {
-	CheckReferences bool
+	CheckReferences     bool
+	CheckInstallSection bool
+	// PodmanVersion is the version of Podman targeted by the unit files. The keys of the generated model are allowed
+	// when it is the zero value.
+	PodmanVersion model.PodmanVersion
+	// MinPodmanVersion is the oldest version of Podman running the unit files like 4.9. Keys supported by newer
+	// versions only are reported when it is set.
+	MinPodmanVersion string
 }
 
 var (
@@ -31,6 +38,7 @@ var (
 	DeprecatedKey         = NewErrorCategory("deprecated-key", LevelWarning)
 	UnsatisfiedDependency = NewErrorCategory("unsatisfied-dependency", LevelError)
 	InvalidReference      = NewErrorCategory("invalid-reference", LevelError)
+	UnsupportedOnTarget   = NewErrorCategory("unsupported-on-target", LevelWarning)
 )
 
 type ValidationError struct {
@@ -75,7 +83,7 @@ func (c ErrorCategory) ErrWithName(validatorName, errName, group, key string,
 
 	return &ValidationError{
 		ErrorCategory: c,
-		Location:      Location{Line: line, Column: column},
+		Location:      Location{Line: line, Column: column, EndLine: line, EndColumn: column},
 		Error:         err,
 		ValidatorName: validatorName,
 		Group:         group,
@@ -84,6 +92,26 @@ func (c ErrorCategory) ErrWithName(validatorName, errName, group, key string,
 	}
 }
 
+// ErrForValue builds an error located at the range of a value
+func (c ErrorCategory) ErrForValue(validatorName, errName string, field model.Field, value model.UnitValue,
+	message string) *ValidationError {
+	return c.ErrForRange(validatorName, errName, field.Group, field.Key, value.Range, message)
+}
+
+// ErrForKey builds an error located at the key name of the assignment holding a value
+func (c ErrorCategory) ErrForKey(validatorName, errName string, field model.Field, value model.UnitValue,
+	message string) *ValidationError {
+	return c.ErrForRange(validatorName, errName, field.Group, field.Key, value.KeyRange, message)
+}
+
+func (c ErrorCategory) ErrForRange(validatorName, errName, group, key string, rng model.Range,
+	message string) *ValidationError {
+	err := c.ErrWithName(validatorName, errName, group, key, rng.Start.Line, rng.Start.Column, message)
+	err.EndLine, err.EndColumn = rng.End.Line, rng.End.Column
+	err.FilePath = rng.File
+	return err
+}
+
 func (c ErrorCategory) ErrSlice(validatorName, errName string,
 	field model.Field, line, column int, message string) []ValidationError {
 	return []ValidationError{*c.ErrForField(validatorName, errName, field, line, column, message)}
@@ -97,9 +125,13 @@ func NewErrorCategory(name string, level Level) ErrorCategory {
 }
 
 type Location struct {
-	FilePath string
-	Line     int
-	Column   int
+	// FilePath is the path of the drop-in where the error is located. It is empty if the error is located in the
+	// unit file itself.
+	FilePath  string
+	Line      int
+	Column    int
+	EndLine   int
+	EndColumn int
 }
 
 type Level string